@@ -0,0 +1,211 @@
+package api
+
+import (
+	db "TestGin/config"
+	res "TestGin/middleware"
+	"TestGin/model"
+	"TestGin/util"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+	"log"
+	"net/http"
+	"net/url"
+)
+
+// ForgotPassword 申请重置密码
+// @Summary 申请重置密码
+// @Description 向邮箱发送重置密码链接。无论邮箱是否注册都返回成功，避免泄露账号是否存在
+// @Tags 账号
+// @Accept json
+// @Produce json
+// @Param request body model.ForgotPasswordRequest true "邮箱"
+// @Success 200 {object} middleware.Response "成功"
+// @Router /api/user/password/forgot [post]
+func ForgotPassword(c *gin.Context) {
+	var req model.ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		res.Error(c, http.StatusBadRequest, err)
+		return
+	}
+	var user model.User
+	if err := db.DB.Where("email = ?", model.NormalizeEmail(req.Email)).First(&user).Error; err == nil {
+		if res.AllowActionMail(user.UUID, res.ActionPasswordReset) {
+			if err = sendActionMail(&user, res.ActionPasswordReset); err != nil {
+				log.Printf("发送重置密码邮件失败: %v", err)
+			}
+		}
+	}
+	res.Success(c, "如果该邮箱已注册，重置链接已发送")
+}
+
+// ResetPassword 重置密码
+// @Summary 重置密码
+// @Description 使用邮件中的令牌设置新密码，令牌只能使用一次；成功后该用户所有会话失效
+// @Tags 账号
+// @Accept json
+// @Produce json
+// @Param request body model.ResetPasswordRequest true "令牌与新密码"
+// @Success 200 {object} middleware.Response "成功"
+// @Router /api/user/password/reset [post]
+func ResetPassword(c *gin.Context) {
+	var req model.ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		res.Error(c, http.StatusBadRequest, err)
+		return
+	}
+	claims, err := res.ParseActionToken(req.Token, res.ActionPasswordReset)
+	if err != nil {
+		res.Error(c, http.StatusBadRequest, err)
+		return
+	}
+	var user model.User
+	if err = db.DB.Where("uuid = ?", claims.UserID).First(&user).Error; err != nil {
+		res.Error(c, http.StatusBadRequest, errors.New("用户不存在"))
+		return
+	}
+	// 先校验密码策略，不满足时令牌仍可再次使用
+	if err = db.GetPasswordPolicy().Validate(req.Password, user.Username, user.Account, user.Email); err != nil {
+		res.Error(c, http.StatusBadRequest, err)
+		return
+	}
+	uuid, err := res.ConsumeActionToken(req.Token, res.ActionPasswordReset)
+	if err != nil {
+		res.Error(c, http.StatusBadRequest, err)
+		return
+	}
+	hashed, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		res.Error(c, http.StatusInternalServerError, err)
+		return
+	}
+	if err = db.DB.Model(&model.User{}).Where("uuid = ?", uuid).Update("password", string(hashed)).Error; err != nil {
+		res.Error(c, http.StatusInternalServerError, err)
+		return
+	}
+	if err = res.RevokeAllUserTokens(uuid); err != nil {
+		log.Printf("撤销用户 %s 的会话失败: %v", uuid, err)
+	}
+	res.RecordAuthEventFromContext(c, res.AuthEventPasswordReset, uuid, "所有会话已撤销")
+	res.Success(c, "密码已重置，请重新登录")
+}
+
+// VerifyEmail 验证邮箱
+// @Summary 验证邮箱
+// @Description 使用邮件中的令牌验证邮箱，未验证状态的用户恢复为 active，重新登录后获得完整权限
+// @Tags 账号
+// @Accept json
+// @Produce json
+// @Param request body model.VerifyEmailRequest true "令牌"
+// @Success 200 {object} middleware.Response "成功"
+// @Router /api/user/email/verify [post]
+func VerifyEmail(c *gin.Context) {
+	var req model.VerifyEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		res.Error(c, http.StatusBadRequest, err)
+		return
+	}
+	claims, err := res.ConsumeEmailVerifyToken(req.Token)
+	if err != nil {
+		res.Error(c, http.StatusBadRequest, err)
+		return
+	}
+	updates := map[string]interface{}{"email_verified": true}
+	var user model.User
+	if err = db.DB.Where("uuid = ?", claims.UserID).First(&user).Error; err != nil {
+		res.Error(c, http.StatusNotFound, errors.New("用户不存在"))
+		return
+	}
+	// 令牌只能验证发送时的邮箱，之后修改过邮箱则失效
+	if user.Email == "" || res.EmailHash(user.Email) != claims.EmailHash {
+		res.Error(c, http.StatusBadRequest, errors.New("邮箱已变更，请重新发送验证邮件"))
+		return
+	}
+	if status := db.Conf.Mail.UnverifiedStatus; status != "" && user.Status == status {
+		updates["status"] = "active"
+	}
+	if err = db.DB.Model(&user).Updates(updates).Error; err != nil {
+		res.Error(c, http.StatusInternalServerError, err)
+		return
+	}
+	res.Success(c, "邮箱验证成功")
+}
+
+// ResendVerification 重新发送验证邮件
+// @Summary 重新发送验证邮件
+// @Tags 账号
+// @Produce json
+// @Param   Authorization  header  string  true  "Bearer Token"
+// @Success 200 {object} middleware.Response "成功"
+// @Failure 429 {object} middleware.Response "发送过于频繁"
+// @Router /api/user/email/resend [post]
+func ResendVerification(c *gin.Context) {
+	user, err := actingUser(c)
+	if err != nil {
+		res.Error(c, http.StatusUnauthorized, err)
+		return
+	}
+	if user.Email == "" {
+		res.Error(c, http.StatusBadRequest, errors.New("未设置邮箱"))
+		return
+	}
+	if user.EmailVerified {
+		res.Error(c, http.StatusConflict, errors.New("邮箱已验证"))
+		return
+	}
+	if !res.AllowActionMail(user.UUID, res.ActionEmailVerify) {
+		res.Error(c, http.StatusTooManyRequests, errors.New("发送过于频繁，请稍后再试"))
+		return
+	}
+	if err = sendActionMail(user, res.ActionEmailVerify); err != nil {
+		res.Error(c, http.StatusInternalServerError, err)
+		return
+	}
+	res.Success(c, "验证邮件已发送")
+}
+
+// sendActionMail 签发一次性令牌并发送对应邮件
+func sendActionMail(user *model.User, action string) error {
+	var (
+		mail util.Mail
+		err  error
+		link string
+	)
+	switch action {
+	case res.ActionPasswordReset:
+		link, err = actionLink("/reset-password", func() (string, error) {
+			return res.CreateActionToken(user.UUID, action, res.PasswordResetExpiration)
+		})
+		mail = util.Mail{
+			Subject: "重置密码",
+			Body: fmt.Sprintf("%s，你好：\n\n请在 %v 内打开以下链接重置密码：\n%s\n\n如果不是你本人操作，请忽略本邮件。\n",
+				user.Username, res.PasswordResetExpiration, link),
+		}
+	case res.ActionEmailVerify:
+		link, err = actionLink("/verify-email", func() (string, error) {
+			return res.CreateEmailVerifyToken(user.UUID, user.Email)
+		})
+		mail = util.Mail{
+			Subject: "验证邮箱",
+			Body: fmt.Sprintf("%s，你好：\n\n请在 %v 内打开以下链接验证邮箱：\n%s\n",
+				user.Username, res.EmailVerifyExpiration, link),
+		}
+	default:
+		return fmt.Errorf("未知的邮件类型: %s", action)
+	}
+	if err != nil {
+		return err
+	}
+	mail.To = user.Email
+	return db.GetMailer().Send(mail)
+}
+
+// actionLink 生成邮件中的链接，令牌放在查询参数中
+func actionLink(path string, issue func() (string, error)) (string, error) {
+	token, err := issue()
+	if err != nil {
+		return "", err
+	}
+	return db.Conf.Mail.BaseURL + path + "?token=" + url.QueryEscape(token), nil
+}
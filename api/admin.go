@@ -0,0 +1,192 @@
+package api
+
+import (
+	db "TestGin/config"
+	res "TestGin/middleware"
+	"TestGin/model"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"time"
+)
+
+// DisableUser 禁用用户
+// @Summary 禁用用户
+// @Description 禁用用户并立即撤销其所有会话。设置 until 为临时禁用，到期后自动解除
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Param   Authorization  header  string  true  "Bearer Token"
+// @Param id path string true "用户UUID"
+// @Param request body model.DisableUserRequest true "原因与到期时间"
+// @Success 200 {object} middleware.Response "成功"
+// @Router /api/user/disable/{id} [post]
+func DisableUser(c *gin.Context) {
+	var req model.DisableUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		res.Error(c, http.StatusBadRequest, err)
+		return
+	}
+	if req.Until != nil && !req.Until.After(time.Now()) {
+		res.Error(c, http.StatusBadRequest, errors.New("解禁时间必须晚于当前时间"))
+		return
+	}
+	uuid := c.Param("id")
+	if uuid == c.GetString("userID") {
+		res.Error(c, http.StatusBadRequest, errors.New("不能禁用自己"))
+		return
+	}
+	var user model.User
+	if err := db.DB.Where("uuid = ?", uuid).First(&user).Error; err != nil {
+		res.Error(c, http.StatusNotFound, errors.New("用户不存在"))
+		return
+	}
+	err := db.DB.Model(&user).Updates(map[string]interface{}{
+		"status":          "disabled",
+		"disabled_reason": req.Reason,
+		"disabled_until":  req.Until,
+	}).Error
+	if err != nil {
+		res.Error(c, http.StatusInternalServerError, err)
+		return
+	}
+	if err = res.DisableUser(user.UUID, req.Reason, req.Until); err != nil {
+		res.Error(c, http.StatusInternalServerError, err)
+		return
+	}
+	res.RecordAuthEventFromContext(c, res.AuthEventUserDisabled, user.UUID, req.Reason)
+	res.Success(c, "用户已禁用")
+}
+
+// EnableUser 解除禁用
+// @Summary 解除禁用
+// @Tags 用户管理
+// @Produce json
+// @Param   Authorization  header  string  true  "Bearer Token"
+// @Param id path string true "用户UUID"
+// @Success 200 {object} middleware.Response "成功"
+// @Router /api/user/enable/{id} [post]
+func EnableUser(c *gin.Context) {
+	var user model.User
+	if err := db.DB.Where("uuid = ?", c.Param("id")).First(&user).Error; err != nil {
+		res.Error(c, http.StatusNotFound, errors.New("用户不存在"))
+		return
+	}
+	if user.Status != "disabled" {
+		res.Error(c, http.StatusConflict, errors.New("用户未被禁用"))
+		return
+	}
+	err := db.DB.Model(&user).Updates(map[string]interface{}{
+		"status":          "active",
+		"disabled_reason": "",
+		"disabled_until":  nil,
+	}).Error
+	if err != nil {
+		res.Error(c, http.StatusInternalServerError, err)
+		return
+	}
+	if err = res.EnableUser(user.UUID); err != nil {
+		res.Error(c, http.StatusInternalServerError, err)
+		return
+	}
+	res.RecordAuthEventFromContext(c, res.AuthEventUserEnabled, user.UUID, "")
+	res.Success(c, "用户已解除禁用")
+}
+
+// UpdateUserRole 修改用户角色
+// @Summary 修改用户角色
+// @Description 角色必须在 rbac.roles 中定义；修改后撤销该用户所有会话，重新登录后新角色生效
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Param   Authorization  header  string  true  "Bearer Token"
+// @Param id path string true "用户UUID"
+// @Param request body model.UpdateRoleRequest true "角色"
+// @Success 200 {object} middleware.Response "成功"
+// @Router /api/user/role/{id} [post]
+func UpdateUserRole(c *gin.Context) {
+	var req model.UpdateRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		res.Error(c, http.StatusBadRequest, err)
+		return
+	}
+	if !res.HasRole(req.Role) {
+		res.Error(c, http.StatusBadRequest, errors.New("角色不存在"))
+		return
+	}
+	uuid := c.Param("id")
+	if uuid == c.GetString("userID") {
+		res.Error(c, http.StatusBadRequest, errors.New("不能修改自己的角色"))
+		return
+	}
+	var user model.User
+	if err := db.DB.Where("uuid = ?", uuid).First(&user).Error; err != nil {
+		res.Error(c, http.StatusNotFound, errors.New("用户不存在"))
+		return
+	}
+	if user.Role == req.Role {
+		res.Success(c, "角色未变化")
+		return
+	}
+	if err := db.DB.Model(&user).Update("role", req.Role).Error; err != nil {
+		res.Error(c, http.StatusInternalServerError, err)
+		return
+	}
+	if err := res.RevokeAllUserTokens(user.UUID); err != nil {
+		res.Error(c, http.StatusInternalServerError, err)
+		return
+	}
+	res.RecordAuthEventFromContext(c, res.AuthEventRoleChange, user.UUID, user.Role+" -> "+req.Role)
+	res.Success(c, "角色已修改")
+}
+
+// ImpersonateUser 模拟登录
+// @Summary 模拟登录
+// @Description 以目标用户身份签发 10 分钟有效的访问令牌（带 act 声明，不签发刷新令牌），用于复现用户反馈的问题。
+// @Description 使用该令牌的响应带有 X-Impersonated-By 头；不能修改密码、资料、会话、令牌等，写操作记录到审计日志
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Param   Authorization  header  string  true  "Bearer Token"
+// @Param id path string true "用户UUID"
+// @Param request body model.ImpersonateRequest true "原因"
+// @Success 200 {object} middleware.Response "access_token"
+// @Router /api/user/impersonate/{id} [post]
+func ImpersonateUser(c *gin.Context) {
+	var req model.ImpersonateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		res.Error(c, http.StatusBadRequest, err)
+		return
+	}
+	uuid := c.Param("id")
+	if uuid == c.GetString("userID") {
+		res.Error(c, http.StatusBadRequest, errors.New("不能模拟自己"))
+		return
+	}
+	var user model.User
+	if err := db.DB.Where("uuid = ?", uuid).First(&user).Error; err != nil {
+		res.Error(c, http.StatusNotFound, errors.New("用户不存在"))
+		return
+	}
+	if err := checkAccountStatus(&user); err != nil {
+		res.Error(c, http.StatusConflict, err)
+		return
+	}
+	// 不能借模拟登录获得同等或更高的管理权限
+	role := loginRole(&user)
+	if res.HasPermission(role, "user:impersonate") {
+		res.Error(c, http.StatusForbidden, errors.New("不能模拟管理员"))
+		return
+	}
+	token, err := res.CreateImpersonationToken(user.UUID, user.Username, role, c.MustGet("claims").(*res.Claims))
+	if err != nil {
+		res.Error(c, http.StatusBadRequest, err)
+		return
+	}
+	res.RecordAuthEventFromContext(c, res.AuthEventImpersonationStart, user.UUID, req.Reason)
+	c.Header(res.ImpersonatedByHeader, c.GetString("userID"))
+	res.Success(c, map[string]interface{}{
+		"access_token": token,
+		"expires_in":   int(res.ImpersonationExpiration.Seconds()),
+	})
+}
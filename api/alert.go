@@ -0,0 +1,158 @@
+package api
+
+import (
+	db "TestGin/config"
+	res "TestGin/middleware"
+	"TestGin/model"
+	"TestGin/util"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// loginDevice 当前请求的设备信息，国家取自本地 GeoIP 数据库
+func loginDevice(c *gin.Context) res.DeviceInfo {
+	device := res.NewDeviceInfo(c)
+	device.Country = db.GetGeoIP().Country(device.IP)
+	return device
+}
+
+// assessLogin 对照登录历史判断是否为异常登录，返回异常原因；未开启或首次登录（没有可对照的历史）时返回空
+func assessLogin(user *model.User, device res.DeviceInfo) []string {
+	if !db.Conf.LoginAlert.Enabled || db.DB == nil {
+		return nil
+	}
+	history := func(query string, args ...interface{}) int64 {
+		var count int64
+		db.DB.Model(&model.AuthEvent{}).
+			Where("user_id = ? AND type = ?", user.UUID, res.AuthEventLoginSuccess).
+			Where(query, args...).Count(&count)
+		return count
+	}
+	if history("1 = 1") == 0 {
+		return nil
+	}
+	var reasons []string
+	if history("fingerprint = ?", device.Fingerprint()) == 0 {
+		reasons = append(reasons, "新设备")
+	}
+	if network := device.Network(); network != "" && history("network = ?", network) == 0 {
+		reasons = append(reasons, "新网络")
+	}
+	if device.Country != "" && history("country = ?", device.Country) == 0 {
+		reasons = append(reasons, "新的国家/地区")
+	}
+	return reasons
+}
+
+// notifyUnusualLogin 记录异常登录并通过提醒发送器通知用户，发送失败只记录日志
+func notifyUnusualLogin(user *model.User, device res.DeviceInfo, reasons []string) {
+	res.RecordAuthEvent(&res.AuthEvent{
+		Type:        res.AuthEventLoginUnusual,
+		UserID:      user.UUID,
+		IP:          device.IP,
+		UserAgent:   device.UserAgent,
+		Reason:      strings.Join(reasons, "、"),
+		Fingerprint: device.Fingerprint(),
+		Network:     device.Network(),
+		Country:     device.Country,
+	})
+	alert := &util.LoginAlert{
+		Username:   user.Username,
+		Email:      user.Email,
+		IP:         device.IP,
+		Country:    device.Country,
+		DeviceName: device.Name,
+		UserAgent:  device.UserAgent,
+		Reasons:    reasons,
+		Time:       time.Now(),
+	}
+	go func() {
+		if err := db.GetNotifier().Notify(alert); err != nil {
+			log.Printf("发送异常登录提醒失败: %v", err)
+		}
+	}()
+}
+
+// beginStepUp 异常登录需要邮件验证码：发送验证码并返回临时令牌，验证通过后才签发令牌
+func beginStepUp(c *gin.Context, user *model.User, reasons []string) {
+	if user.Email == "" {
+		res.Error(c, http.StatusForbidden, errors.New("异常登录需要邮件验证，但账号未设置邮箱"))
+		return
+	}
+	token, code, err := res.CreateStepUpToken(user.UUID)
+	if err != nil {
+		res.Error(c, http.StatusInternalServerError, err)
+		return
+	}
+	mail := util.Mail{
+		To:      user.Email,
+		Subject: "登录验证码",
+		Body: fmt.Sprintf("%s，你好：\n\n检测到%s登录，验证码为 %s，%v 内有效。\n\n如果不是你本人操作，请立即修改密码。\n",
+			user.Username, strings.Join(reasons, "、"), code, res.StepUpExpiration),
+	}
+	if err = db.GetMailer().Send(mail); err != nil {
+		res.Error(c, http.StatusInternalServerError, errors.New("发送验证码失败"))
+		return
+	}
+	res.Success(c, map[string]interface{}{
+		"UUID":             user.UUID,
+		"step_up_required": true,
+		"step_up_method":   "email",
+		"step_up_token":    token,
+		"reasons":          reasons,
+	})
+}
+
+// LoginStepUp 异常登录二次验证
+// @Summary 异常登录二次验证
+// @Description 新设备或新网络登录时，使用登录返回的 step_up_token 加邮件验证码换取令牌
+// @Tags 登录
+// @Accept json
+// @Produce json
+// @Param request body model.StepUpLoginRequest true "临时令牌与验证码"
+// @Success 200 {object} middleware.Response "成功"
+// @Failure 423 {object} middleware.Response "多次失败被临时锁定 (code=42301)"
+// @Failure 429 {object} middleware.Response "失败后退避中 (code=42901)"
+// @Router /api/user/login/step-up [post]
+func LoginStepUp(c *gin.Context) {
+	var req model.StepUpLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		res.Error(c, http.StatusBadRequest, err)
+		return
+	}
+	claims, err := res.ParseStepUpToken(req.StepUpToken)
+	if err != nil {
+		res.Error(c, http.StatusUnauthorized, err)
+		return
+	}
+	// 验证码同样受登录失败次数限制
+	identifier, ip := "stepup:"+claims.UserID, c.ClientIP()
+	if err = res.CheckLoginAllowed(identifier, ip); err != nil {
+		loginBlocked(c, err)
+		return
+	}
+	if !res.VerifyStepUpCode(claims, req.Code) {
+		res.RecordLoginFailure(identifier, ip)
+		res.RecordAuthEventFromContext(c, res.AuthEventLoginFailure, claims.UserID, "登录验证码错误")
+		res.Error(c, http.StatusBadRequest, errors.New("验证码错误"))
+		return
+	}
+	res.ResetLoginFailures(identifier, ip)
+	var user model.User
+	if err = db.DB.Where("uuid = ?", claims.UserID).First(&user).Error; err != nil {
+		res.Error(c, http.StatusUnauthorized, errors.New("用户不存在"))
+		return
+	}
+	if err = checkAccountStatus(&user); err != nil {
+		res.RecordAuthEventFromContext(c, res.AuthEventLoginFailure, user.UUID, err.Error())
+		res.ErrorWithCode(c, http.StatusForbidden, res.CodeAccountDisabled, err)
+		return
+	}
+	device := loginDevice(c)
+	issueLogin(c, &user, device, assessLogin(&user, device))
+}
@@ -4,11 +4,17 @@ import (
 	db "TestGin/config"
 	res "TestGin/middleware"
 	"TestGin/model"
+	"TestGin/util"
+	"context"
 	"errors"
+	"fmt"
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 	"log"
+	"net/http"
 	"strconv"
+	"strings"
+	"time"
 )
 
 // DeleteArticle 删除文章
@@ -18,102 +24,491 @@ import (
 // @Param id path int true "文章ID"
 // @Param   Authorization  header  string  true  "Bearer Token"
 // @Success 200 {object} string  "文章信息"
-// @Router /api/articles/delete/:id [delete]
+// @Failure 403 {object} middleware.Response "不是文章作者"
+// @Router /api/article/delete/{id} [delete]
 func DeleteArticle(c *gin.Context) {
 	// 获取路径参数 id
 	id, _ := strconv.Atoi(c.Param("id"))
-	ctx := db.DB.Delete(model.Article{}, id)
-	log.Printf("结果：%v\n", ctx)
-
-	// TODO: 实现删除逻辑，例如通过数据库操作删除对应文章
+	var article model.Article
+	if err := db.DB.First(&article, id).Error; err != nil {
+		res.Error(c, http.StatusNotFound, errors.New("文章不存在"))
+		return
+	}
+	// 只能删除自己的文章，拥有审核权限的角色除外
+	if err := checkOwner(c, uint(article.UserID), "article:moderate"); err != nil {
+		ownershipError(c, err)
+		return
+	}
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := clearArticleTaxonomy(tx, article.ID); err != nil {
+			return err
+		}
+		return tx.Delete(&article).Error
+	})
+	if err != nil {
+		res.Error(c, http.StatusInternalServerError, err)
+		return
+	}
+	invalidateArticleCache(article.ID)
+	removeArticleIndex(article.ID)
 	res.Success(c, "文章已删除")
 }
 
 // GetArticle 查询文章
 // @Summary 查询文章
-// @Description 查询文章
+// @Description 已发布的文章所有人可见；未发布的文章只有作者与拥有 article:moderate 或 article:publish 权限的角色可见
 // @Tags 文章
 // @Param id path int true "文章ID"
 // @Param   Authorization  header  string  true  "Bearer Token"
 // @Success 200 {object} model.ArticleResponse  "文章信息"
-// @Router /api/article/get/:id [get]
+// @Failure 404 {object} middleware.Response "文章不存在或无权查看"
+// @Router /api/article/get/{id} [get]
 func GetArticle(c *gin.Context) {
 	var article model.Article
 	id, _ := strconv.Atoi(c.Param("id"))
 	// 查询文章
-	if err := db.DB.Table("articles").
-		Where("id = ? AND status = 0", id).
-		First(&article).Error; err != nil {
+	if err := db.DB.Where("id = ?", id).First(&article).Error; err != nil {
 		// 处理不同类型的错误
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			res.Error(c, 404, err)
+			res.Error(c, 404, errors.New("文章不存在"))
 		} else {
 			res.Error(c, 400, err)
 		}
 		return
 	}
+	if article.Status != model.Published {
+		// 未发布的内容因人而异，不能进入按 URL 共享的缓存
+		res.SkipCache(c)
+		if !canViewUnpublished(c, &article) {
+			res.Error(c, http.StatusNotFound, errors.New("文章不存在"))
+			return
+		}
+	}
 	// 转换为响应格式并返回
-	ar := model.ArticleToResponse(article)
-	res.Success(c, ar)
+	items, err := articleResponses([]model.Article{article})
+	if err != nil {
+		res.Error(c, http.StatusInternalServerError, err)
+		return
+	}
+	res.Success(c, items[0])
+}
+
+// canViewUnpublished 作者本人与审核人员可以查看未发布的文章
+func canViewUnpublished(c *gin.Context, article *model.Article) bool {
+	if res.Can(c, "article:moderate") || res.Can(c, "article:publish") {
+		return true
+	}
+	user, err := actingUser(c)
+	return err == nil && int64(user.ID) == article.UserID
+}
+
+// countArticleView 文章详情成功返回后异步累加浏览次数，缓存命中的请求同样计数
+func countArticleView(c *gin.Context) {
+	c.Next()
+	if c.Writer.Status() != http.StatusOK {
+		return
+	}
+	id, _ := strconv.Atoi(c.Param("id"))
+	go func() {
+		// UpdateColumn 不触发钩子，也不修改 updated_at
+		if err := db.DB.Model(&model.Article{}).Where("id = ?", id).UpdateColumn("views", gorm.Expr("views + 1")).Error; err != nil {
+			log.Printf("累加浏览次数失败: %v", err)
+		}
+	}()
+}
+
+// articleCacheKey 文章详情的缓存 key，状态或内容变化时按文章ID清除
+func articleCacheKey(c *gin.Context) string {
+	return "cache:article:" + c.Param("id")
+}
+
+// invalidateArticleCache 清除文章详情缓存，失败只记录日志，缓存最多在 TTL 后过期
+func invalidateArticleCache(id int64) {
+	red := db.GetRedisClient()
+	if red == nil {
+		return
+	}
+	if err := red.Del(context.Background(), "cache:article:"+strconv.FormatInt(id, 10)).Err(); err != nil {
+		log.Printf("清除文章缓存失败: %v", err)
+	}
+}
+
+// articleSortColumns 排序方式对应的列，均按 (列, id) 倒序做游标分页
+var articleSortColumns = map[string]string{
+	"newest":  "created_at",
+	"updated": "updated_at",
+	"popular": "views",
+}
+
+// articleCursor 列表游标：上一页最后一条的排序列取值与 ID，排序方式不同的游标不能混用
+type articleCursor struct {
+	Sort  string     `json:"s"`
+	Time  *time.Time `json:"t,omitempty"`
+	Views int64      `json:"v,omitempty"`
+	ID    int64      `json:"i"`
+}
+
+// ListArticle 文章列表
+// @Summary 文章列表
+// @Description 按作者、状态、标签、分类、创建时间筛选，按最新 (newest)、最近更新 (updated)、热度 (popular) 排序，使用 next_cursor 翻页。
+// @Description 默认只返回已发布的文章；其他状态只能查看自己的文章，拥有 article:moderate 或 article:publish 权限的角色不受限制
+// @Tags 文章
+// @Produce json
+// @Param   Authorization  header  string  true  "Bearer Token"
+// @Param user_id query int false "作者ID"
+// @Param status query string false "状态 (draft、pending、published、rejected、archived)，默认 published"
+// @Param tag query string false "标签"
+// @Param category_id query int false "分类ID，包含下级分类"
+// @Param from query string false "创建时间起 (RFC3339)"
+// @Param to query string false "创建时间止 (RFC3339)"
+// @Param sort query string false "排序方式 (newest、updated、popular)，默认 newest"
+// @Param cursor query string false "上一页返回的 next_cursor"
+// @Param size query int false "每页条数，默认 20，最多 100"
+// @Success 200 {object} model.ArticlePage "文章列表"
+// @Failure 403 {object} middleware.Response "无权查看他人未发布的文章"
+// @Router /api/article/list [get]
+func ListArticle(c *gin.Context) {
+	var q model.ArticleListQuery
+	if err := c.ShouldBindQuery(&q); err != nil {
+		res.Error(c, http.StatusBadRequest, err)
+		return
+	}
+	if q.Sort == "" {
+		q.Sort = "newest"
+	}
+	if q.Size == 0 {
+		q.Size = 20
+	}
+	status := model.Published
+	if q.Status != "" {
+		status, _ = model.ParseArticleStatus(q.Status)
+	}
+
+	tx := db.DB.Model(&model.Article{}).Where("status = ?", status)
+	if status != model.Published {
+		// 未发布的列表因人而异，不写入按 URL 共享的缓存
+		res.SkipCache(c)
+		role := c.GetString("role")
+		if !res.HasPermission(role, "article:moderate") && !res.HasPermission(role, "article:publish") {
+			user, err := actingUser(c)
+			if err != nil {
+				res.Error(c, http.StatusUnauthorized, err)
+				return
+			}
+			if q.UserID != 0 && q.UserID != int64(user.ID) {
+				res.Error(c, http.StatusForbidden, errors.New("无权查看他人未发布的文章"))
+				return
+			}
+			q.UserID = int64(user.ID)
+		}
+	}
+	if q.UserID != 0 {
+		tx = tx.Where("user_id = ?", q.UserID)
+	}
+	if q.Tag != "" {
+		tx = tx.Where("id IN (?)", db.DB.Table("article_tags").Select("article_tags.article_id").
+			Joins("JOIN tags ON tags.id = article_tags.tag_id").Where("tags.name = ?", q.Tag))
+	}
+	if q.Category != 0 {
+		// 包含下级分类中的文章
+		categories, err := loadCategories()
+		if err != nil {
+			res.Error(c, http.StatusInternalServerError, err)
+			return
+		}
+		tx = tx.Where("id IN (?)", db.DB.Model(&model.ArticleCategory{}).Select("article_id").
+			Where("category_id IN ?", categoryDescendants(categories, q.Category)))
+	}
+	if q.From != nil {
+		tx = tx.Where("created_at >= ?", *q.From)
+	}
+	if q.To != nil {
+		tx = tx.Where("created_at < ?", *q.To)
+	}
+
+	column := articleSortColumns[q.Sort]
+	if q.Cursor != "" {
+		var cur articleCursor
+		if err := util.DecodeCursor(q.Cursor, &cur); err != nil || cur.Sort != q.Sort {
+			res.Error(c, http.StatusBadRequest, util.ErrInvalidCursor)
+			return
+		}
+		var value interface{} = cur.Views
+		if q.Sort != "popular" {
+			if cur.Time == nil {
+				res.Error(c, http.StatusBadRequest, util.ErrInvalidCursor)
+				return
+			}
+			value = *cur.Time
+		}
+		// 键集分页：从上一页最后一条之后继续，不使用 OFFSET，深翻页同样走索引
+		tx = tx.Where(column+" < ? OR ("+column+" = ? AND id < ?)", value, value, cur.ID)
+	}
+
+	var articles []model.Article
+	// 多取一条判断是否还有下一页
+	if err := tx.Order(column + " DESC, id DESC").Limit(q.Size + 1).Find(&articles).Error; err != nil {
+		res.Error(c, http.StatusInternalServerError, err)
+		return
+	}
+	var page model.ArticlePage
+	if len(articles) > q.Size {
+		articles = articles[:q.Size]
+		last := articles[len(articles)-1]
+		cur := articleCursor{Sort: q.Sort, ID: last.ID}
+		switch q.Sort {
+		case "newest":
+			cur.Time = &last.CreatedAt
+		case "updated":
+			cur.Time = &last.UpdatedAt
+		default:
+			cur.Views = last.Views
+		}
+		next, err := util.EncodeCursor(cur)
+		if err != nil {
+			res.Error(c, http.StatusInternalServerError, err)
+			return
+		}
+		page.NextCursor = next
+	}
+	items, err := articleResponses(articles)
+	if err != nil {
+		res.Error(c, http.StatusInternalServerError, err)
+		return
+	}
+	page.Items = items
+	res.Success(c, page)
 }
 
+// errStatusConflict 状态流转不合法，或状态已被其他请求修改
+var errStatusConflict = errors.New("文章状态已变更，请刷新后重试")
+
 // UpdateArticleStatus 更新文章状态
 // @Summary 更新文章状态
-// @Description 更新文章状态
+// @Description 需要 article:status 权限。按状态机流转：作者可以提交审核、撤回、归档；article:publish 审核通过或驳回（驳回需填写原因）；article:moderate 可以归档、撤下已发布文章。
+// @Description 不允许的流转返回 409，没有权限返回 403；每次变更记录在 article_status_history 中
 // @Tags 文章
+// @Accept json
+// @Produce json
 // @Param id path int true "文章ID"
 // @Param   Authorization  header  string  true  "Bearer Token"
-// @Param request body model.ArticleStatus true "请求体 (status: 0=Draft, 1=Pending, 2=Published)"
-// @Success 200 {object} middleware.Response "更新成功返回"
-// @Router /api/article/{uuid}/status [put]
+// @Param request body model.UpdateArticleStatusRequest true "目标状态 (draft、pending、published、rejected、archived) 与原因"
+// @Success 200 {object} model.ArticleResponse "变更后的文章"
+// @Failure 403 {object} middleware.Response "没有执行该流转的权限"
+// @Failure 409 {object} middleware.Response "不允许的状态流转"
+// @Router /api/article/{id}/status [put]
 func UpdateArticleStatus(c *gin.Context) {
+	var req model.UpdateArticleStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		res.Error(c, http.StatusBadRequest, err)
+		return
+	}
+	to, _ := model.ParseArticleStatus(req.Status)
+	id, _ := strconv.Atoi(c.Param("id"))
 	var article model.Article
-	//article.Status = model.Draft
-	aid := c.Param("uuid")
-	//打印请求体
-	sta := c.ShouldBindJSON(&article.Status)
-	log.Printf("id:%s----status:%s\n", aid, sta)
-	res.Success(c, "操作成功")
+	if err := db.DB.First(&article, id).Error; err != nil {
+		res.Error(c, http.StatusNotFound, errors.New("文章不存在"))
+		return
+	}
+	user, err := actingUser(c)
+	if err != nil {
+		res.Error(c, http.StatusUnauthorized, err)
+		return
+	}
+	from := article.Status
+	rule, ok := model.FindArticleTransition(from, to)
+	if !ok {
+		res.Error(c, http.StatusConflict, fmt.Errorf("文章不能从 %s 变更为 %s", from, to))
+		return
+	}
+	isAuthor := int64(user.ID) == article.UserID
+	if !(rule.Author && isAuthor) && !(rule.Permission != "" && res.Can(c, rule.Permission)) {
+		res.Error(c, http.StatusForbidden, fmt.Errorf("无权将文章从 %s 变更为 %s", from, to))
+		return
+	}
+	req.Reason = strings.TrimSpace(req.Reason)
+	if rule.ReasonRequired && req.Reason == "" {
+		res.Error(c, http.StatusBadRequest, errors.New("该操作需要填写原因"))
+		return
+	}
+
+	err = db.DB.Transaction(func(tx *gorm.DB) error {
+		// 条件更新：状态在读取之后被其他请求修改时不覆盖
+		result := tx.Model(&model.Article{}).
+			Where("id = ? AND status = ?", article.ID, from).
+			Updates(map[string]interface{}{"status": to, "status_name": to.String()})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errStatusConflict
+		}
+		return tx.Create(&model.ArticleStatusHistory{
+			ArticleID:  article.ID,
+			FromStatus: from,
+			ToStatus:   to,
+			ActorID:    user.ID,
+			Reason:     req.Reason,
+		}).Error
+	})
+	if errors.Is(err, errStatusConflict) {
+		res.Error(c, http.StatusConflict, err)
+		return
+	}
+	if err != nil {
+		res.Error(c, http.StatusInternalServerError, err)
+		return
+	}
+	invalidateArticleCache(article.ID)
+	article.Status = to
+	indexArticle(article)
+	res.Success(c, model.ArticleToResponse(article))
+}
+
+// ListArticleStatusHistory 文章状态变更记录
+// @Summary 文章状态变更记录
+// @Description 作者本人或拥有 article:moderate 权限的角色查看，按时间倒序
+// @Tags 文章
+// @Produce json
+// @Param id path int true "文章ID"
+// @Param   Authorization  header  string  true  "Bearer Token"
+// @Success 200 {array} model.ArticleStatusHistory "变更记录"
+// @Failure 403 {object} middleware.Response "不是文章作者"
+// @Router /api/article/{id}/history [get]
+func ListArticleStatusHistory(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	var article model.Article
+	if err := db.DB.First(&article, id).Error; err != nil {
+		res.Error(c, http.StatusNotFound, errors.New("文章不存在"))
+		return
+	}
+	if err := checkOwner(c, uint(article.UserID), "article:moderate"); err != nil {
+		ownershipError(c, err)
+		return
+	}
+	var history []model.ArticleStatusHistory
+	if err := db.DB.Where("article_id = ?", article.ID).Order("id DESC").Find(&history).Error; err != nil {
+		res.Error(c, http.StatusInternalServerError, err)
+		return
+	}
+	res.Success(c, history)
 }
 
 // UpdateArticle 更新文章
 // @Summary 更新文章
-// @Description 更新文章
+// @Description 更新文章，标题或内容变化时生成新的修订版本
 // @Tags 文章
 // @Param   Authorization  header  string  true  "Bearer Token"
 // @Param id path int true "文章ID"
-// @Param request body model.Article true "请求体"
+// @Param request body model.ArticleRequest true "请求体，省略的字段保持不变"
 // @Success 200 {object} middleware.Response "更新成功返回"
-// @Router /api/article/{id} [put]
+// @Failure 403 {object} middleware.Response "不是文章作者"
+// @Router /api/article/update/{id} [put]
 func UpdateArticle(c *gin.Context) {
-	var article model.Article
-	if err := c.ShouldBindJSON(&article); err != nil {
+	var req model.ArticleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
 		res.Error(c, 400, err)
 		return
 	}
-	log.Printf("%+v\n", article)
+	id, _ := strconv.Atoi(c.Param("id"))
+	var article model.Article
+	if err := db.DB.First(&article, id).Error; err != nil {
+		res.Error(c, http.StatusNotFound, errors.New("文章不存在"))
+		return
+	}
+	if err := checkOwner(c, uint(article.UserID), "article:moderate"); err != nil {
+		ownershipError(c, err)
+		return
+	}
+	user, err := actingUser(c)
+	if err != nil {
+		res.Error(c, http.StatusUnauthorized, err)
+		return
+	}
+	// 只更新标题、内容、标签与分类，空标题或空内容保持原值；标签计数、修订版本与文章在同一事务中修改
+	updated := article
+	if req.Title != "" {
+		updated.Title = req.Title
+	}
+	if req.Content != "" {
+		updated.Content = req.Content
+	}
+	changed := updated.Title != article.Title || updated.Content != article.Content
+	err = db.DB.Transaction(func(tx *gorm.DB) error {
+		if changed {
+			if err := ensureBaseRevision(tx, &article); err != nil {
+				return err
+			}
+			if err := tx.Model(&article).Updates(model.Article{Title: req.Title, Content: req.Content}).Error; err != nil {
+				return err
+			}
+			if _, err := saveRevision(tx, &updated, user.ID, nil); err != nil {
+				return err
+			}
+		}
+		return saveArticleTaxonomy(tx, article.ID, &req)
+	})
+	if errors.Is(err, errCategoryNotFound) {
+		res.Error(c, http.StatusBadRequest, err)
+		return
+	}
+	if err != nil {
+		res.Error(c, http.StatusInternalServerError, err)
+		return
+	}
+	invalidateArticleCache(article.ID)
+	indexArticle(updated)
 	res.Success(c, "更新文章成功")
 }
 
 // AddArticle 添加文章
 // @Summary 添加文章
-// @Description 添加文章
+// @Description 添加文章，新文章为草稿状态；可同时设置标签（最多 10 个，不存在时创建）与分类（最多 5 个）
 // @Tags 文章
+// @Accept json
+// @Produce json
 // @Param   Authorization  header  string  true  "Bearer Token"
-// @Param request body model.Article true "请求体"
+// @Param request body model.ArticleRequest true "请求体"
 // @Success 200 {object} middleware.Response "添加成功返回"
+// @Failure 400 {object} middleware.Response "分类不存在"
 // @Router /api/article/add [post]
 func AddArticle(c *gin.Context) {
-	var article model.Article
-	if err := c.ShouldBindJSON(&article); err != nil {
+	var req model.ArticleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
 		res.Error(c, 400, err)
 		return
 	}
 
-	log.Printf("%+v\n", article)
-	if err := db.DB.Create(&article).Error; err != nil {
+	// 作者取当前登录用户
+	user, err := actingUser(c)
+	if err != nil {
+		res.Error(c, http.StatusUnauthorized, err)
+		return
+	}
+	// 新文章一律为草稿，发布需要走状态流转
+	article := model.Article{
+		UserID:  int64(user.ID),
+		Title:   req.Title,
+		Content: req.Content,
+		Status:  model.Draft,
+	}
+	err = db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&article).Error; err != nil {
+			return err
+		}
+		if _, err := saveRevision(tx, &article, user.ID, nil); err != nil {
+			return err
+		}
+		return saveArticleTaxonomy(tx, article.ID, &req)
+	})
+	if errors.Is(err, errCategoryNotFound) {
+		res.Error(c, http.StatusBadRequest, err)
+		return
+	}
+	if err != nil {
 		res.Error(c, 500, err)
 		return
 	}
+	indexArticle(article)
 	res.Success(c, "添加文章成功")
 }
@@ -32,7 +32,6 @@ type UploadItems struct {
 // @Accept  multipart/form-data
 // @Param content formData string true "评论内容"
 // @Param postId formData int true "所属帖子ID"
-// @Param userId formData int true "评论用户ID"
 // @Param parentId formData int false "父评论ID"
 // @Param type formData int true "资源类型 image/video"
 // @Param files formData file true "上传文件"
@@ -40,24 +39,29 @@ type UploadItems struct {
 // @Success 200 {object} res.Response "{"code":200,"data":{},"msg":"操作成功"}"
 // @Router /api/comment/add [post]
 func AddComment(c *gin.Context) {
+	// 评论者取当前登录用户，不再信任表单中的 userId；先确认身份再落盘文件
+	user, err := actingUser(c)
+	if err != nil {
+		res.Error(c, http.StatusUnauthorized, err)
+		return
+	}
 
 	content := c.PostForm("content")
 	typeFile, _ := strconv.Atoi(c.PostForm("type"))
 
 	postIDInt, _ := strconv.Atoi(c.PostForm("postId"))
-	userIDInt, _ := strconv.Atoi(c.PostForm("userId"))
 	parentIDInt, _ := strconv.Atoi(c.PostForm("parentId"))
 	parentIDTo := uint(parentIDInt)
 	parentIDPtr := &parentIDTo // parentIDPtr 类型为 *uint
 
 	formFile, err := c.MultipartForm()
-	files := formFile.File["files"]
-	uploadPath := "static"
-
 	if err != nil {
-		res.Error(c, http.StatusInternalServerError, err)
+		res.Error(c, http.StatusBadRequest, err)
 		return
 	}
+	files := formFile.File["files"]
+	uploadPath := "static"
+
 	var allowedTypes = []string{"image/", "video/"}
 	var allowedExt = []string{".png", ".jpg", ".jpeg", ".gif", ".mp4"}
 	var fileList []string
@@ -120,7 +124,7 @@ func AddComment(c *gin.Context) {
 	form := model.Comment{
 		Content:  content,
 		PostID:   uint(postIDInt),
-		UserID:   uint(userIDInt),
+		UserID:   user.ID,
 		ParentID: parentIDPtr,
 	}
 
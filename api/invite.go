@@ -0,0 +1,210 @@
+package api
+
+import (
+	db "TestGin/config"
+	res "TestGin/middleware"
+	"TestGin/model"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 注册模式
+const (
+	RegisterModeOpen   = "open"
+	RegisterModeInvite = "invite"
+	RegisterModeClosed = "closed"
+)
+
+// 管理所有邀请码的权限：不受普通用户的次数与数量限制，可以查看、作废他人的邀请码
+const manageInvitePermission = "invite:manage"
+
+var errInviteInvalid = errors.New("邀请码无效、已过期或已用完")
+
+// registerMode 当前注册模式，未配置时为 open
+func registerMode() string {
+	switch mode := strings.ToLower(db.Conf.Register.Mode); mode {
+	case RegisterModeInvite, RegisterModeClosed:
+		return mode
+	default:
+		return RegisterModeOpen
+	}
+}
+
+// CreateInvite 创建邀请码
+// @Summary 创建邀请码
+// @Description 普通用户的使用次数与同时有效数量受 register.usermaxuses / usermaxactive 限制
+// @Tags 邀请码
+// @Accept json
+// @Produce json
+// @Param   Authorization  header  string  true  "Bearer Token"
+// @Param request body model.CreateInviteRequest true "使用次数与有效天数"
+// @Success 200 {object} model.InviteCode "邀请码"
+// @Router /api/user/invites [post]
+func CreateInvite(c *gin.Context) {
+	var req model.CreateInviteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		res.Error(c, http.StatusBadRequest, err)
+		return
+	}
+	user, err := actingUser(c)
+	if err != nil {
+		res.Error(c, http.StatusUnauthorized, err)
+		return
+	}
+	if req.MaxUses == 0 {
+		req.MaxUses = 1
+	}
+	ttl := db.Conf.Register.InviteExpiration
+	if req.ExpiresInDays > 0 {
+		ttl = time.Duration(req.ExpiresInDays) * 24 * time.Hour
+	}
+	if ttl <= 0 {
+		ttl = 7 * 24 * time.Hour
+	}
+
+	if !res.HasPermission(c.GetString("role"), manageInvitePermission) {
+		maxUses, maxActive := db.Conf.Register.UserMaxUses, db.Conf.Register.UserMaxActive
+		if maxUses <= 0 {
+			maxUses = 5
+		}
+		if maxActive <= 0 {
+			maxActive = 5
+		}
+		if req.MaxUses > maxUses {
+			res.Error(c, http.StatusBadRequest, fmt.Errorf("邀请码最多可使用 %d 次", maxUses))
+			return
+		}
+		var active int64
+		err = db.DB.Model(&model.InviteCode{}).
+			Where("creator_id = ? AND uses < max_uses AND (expires_at IS NULL OR expires_at > ?)", user.ID, time.Now()).
+			Count(&active).Error
+		if err != nil {
+			res.Error(c, http.StatusInternalServerError, err)
+			return
+		}
+		if active >= int64(maxActive) {
+			res.Error(c, http.StatusConflict, fmt.Errorf("最多同时持有 %d 个有效邀请码", maxActive))
+			return
+		}
+	}
+
+	code, err := generateInviteCode()
+	if err != nil {
+		res.Error(c, http.StatusInternalServerError, err)
+		return
+	}
+	expiresAt := time.Now().Add(ttl)
+	invite := model.InviteCode{
+		Code:      code,
+		CreatorID: user.ID,
+		MaxUses:   req.MaxUses,
+		ExpiresAt: &expiresAt,
+	}
+	if err = db.DB.Create(&invite).Error; err != nil {
+		res.Error(c, http.StatusInternalServerError, err)
+		return
+	}
+	res.Success(c, invite)
+}
+
+// ListInvites 邀请码列表
+// @Summary 邀请码列表
+// @Description 当前用户创建的邀请码；拥有 invite:manage 权限时 all=true 返回所有邀请码
+// @Tags 邀请码
+// @Produce json
+// @Param   Authorization  header  string  true  "Bearer Token"
+// @Param all query bool false "是否返回所有用户的邀请码"
+// @Success 200 {array} model.InviteCode "邀请码列表"
+// @Router /api/user/invites [get]
+func ListInvites(c *gin.Context) {
+	user, err := actingUser(c)
+	if err != nil {
+		res.Error(c, http.StatusUnauthorized, err)
+		return
+	}
+	tx := db.DB.Order("id DESC")
+	if c.Query("all") != "true" || !res.HasPermission(c.GetString("role"), manageInvitePermission) {
+		tx = tx.Where("creator_id = ?", user.ID)
+	}
+	var invites []model.InviteCode
+	if err = tx.Find(&invites).Error; err != nil {
+		res.Error(c, http.StatusInternalServerError, err)
+		return
+	}
+	res.Success(c, invites)
+}
+
+// RevokeInvite 作废邀请码
+// @Summary 作废邀请码
+// @Description 作废自己创建的邀请码，拥有 invite:manage 权限时可以作废任意邀请码；已注册的用户不受影响
+// @Tags 邀请码
+// @Produce json
+// @Param   Authorization  header  string  true  "Bearer Token"
+// @Param id path int true "邀请码ID"
+// @Success 200 {object} middleware.Response "成功"
+// @Router /api/user/invites/{id} [delete]
+func RevokeInvite(c *gin.Context) {
+	user, err := actingUser(c)
+	if err != nil {
+		res.Error(c, http.StatusUnauthorized, err)
+		return
+	}
+	id, _ := strconv.Atoi(c.Param("id"))
+	tx := db.DB.Where("id = ?", id)
+	if !res.HasPermission(c.GetString("role"), manageInvitePermission) {
+		tx = tx.Where("creator_id = ?", user.ID)
+	}
+	result := tx.Delete(&model.InviteCode{})
+	if result.Error != nil {
+		res.Error(c, http.StatusInternalServerError, result.Error)
+		return
+	}
+	if result.RowsAffected == 0 {
+		res.Error(c, http.StatusNotFound, errors.New("邀请码不存在"))
+		return
+	}
+	res.Success(c, "邀请码已作废")
+}
+
+// redeemInvite 在注册事务中使用邀请码：条件更新保证并发注册不会超过使用次数
+func redeemInvite(tx *gorm.DB, code string) (*model.InviteCode, error) {
+	var invite model.InviteCode
+	err := tx.Where("code = ?", strings.ToUpper(strings.TrimSpace(code))).First(&invite).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errInviteInvalid
+	}
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if !invite.Usable(now) {
+		return nil, errInviteInvalid
+	}
+	result := tx.Model(&model.InviteCode{}).
+		Where("id = ? AND uses < max_uses AND (expires_at IS NULL OR expires_at > ?)", invite.ID, now).
+		UpdateColumn("uses", gorm.Expr("uses + 1"))
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, errInviteInvalid
+	}
+	return &invite, nil
+}
+
+// generateInviteCode 生成 16 位大写字母与数字组成的邀请码
+func generateInviteCode() (string, error) {
+	buf := make([]byte, 10)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(buf), nil
+}
@@ -0,0 +1,19 @@
+package api
+
+import (
+	res "TestGin/middleware"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// JWKS 公钥集合
+// @Summary 获取 JWT 验签公钥
+// @Description 返回 RS256/EdDSA 公钥集合 (JWKS)，HS256 密钥不会公开
+// @Tags 登录
+// @Produce json
+// @Success 200 {object} middleware.JWKSet "公钥集合"
+// @Router /.well-known/jwks.json [get]
+func JWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, res.GetJWKS())
+}
@@ -0,0 +1,267 @@
+package api
+
+import (
+	db "TestGin/config"
+	res "TestGin/middleware"
+	"TestGin/model"
+	"TestGin/util"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// 每次生成的恢复码数量
+const recoveryCodeCount = 10
+
+// MFAEnroll 开始绑定两步验证
+// @Summary 绑定两步验证
+// @Description 生成 TOTP 密钥，返回 otpauth 链接与二维码 PNG (Base64)，需调用 confirm 提交动态码后才会生效
+// @Tags 两步验证
+// @Produce json
+// @Param   Authorization  header  string  true  "Bearer Token"
+// @Success 200 {object} middleware.Response "成功"
+// @Failure 409 {object} middleware.Response "已开启两步验证"
+// @Router /api/user/mfa/enroll [post]
+func MFAEnroll(c *gin.Context) {
+	user, err := actingUser(c)
+	if err != nil {
+		res.Error(c, http.StatusUnauthorized, err)
+		return
+	}
+	if user.TOTPEnabled {
+		res.Error(c, http.StatusConflict, errors.New("已开启两步验证"))
+		return
+	}
+	secret, err := util.GenerateTOTPSecret()
+	if err != nil {
+		res.Error(c, http.StatusInternalServerError, err)
+		return
+	}
+	if err = db.DB.Model(user).Update("totp_secret", secret).Error; err != nil {
+		res.Error(c, http.StatusInternalServerError, err)
+		return
+	}
+	account := user.Email
+	if account == "" {
+		account = user.Username
+	}
+	uri := util.TOTPProvisioningURI(mfaIssuer(), account, secret)
+	png, err := util.QRCodePNG(uri, 256)
+	if err != nil {
+		res.Error(c, http.StatusInternalServerError, err)
+		return
+	}
+	res.Success(c, map[string]interface{}{
+		"secret":           secret,
+		"provisioning_uri": uri,
+		"qr_png":           "data:image/png;base64," + base64.StdEncoding.EncodeToString(png),
+	})
+}
+
+// MFAConfirm 确认绑定两步验证
+// @Summary 确认绑定两步验证
+// @Description 提交认证器 App 上的动态码完成绑定，返回一次性恢复码（只显示这一次）
+// @Tags 两步验证
+// @Accept json
+// @Produce json
+// @Param   Authorization  header  string  true  "Bearer Token"
+// @Param request body model.MFACodeRequest true "动态码"
+// @Success 200 {object} middleware.Response "恢复码列表"
+// @Router /api/user/mfa/confirm [post]
+func MFAConfirm(c *gin.Context) {
+	var req model.MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		res.Error(c, http.StatusBadRequest, err)
+		return
+	}
+	user, err := actingUser(c)
+	if err != nil {
+		res.Error(c, http.StatusUnauthorized, err)
+		return
+	}
+	if user.TOTPEnabled {
+		res.Error(c, http.StatusConflict, errors.New("已开启两步验证"))
+		return
+	}
+	if user.TOTPSecret == "" {
+		res.Error(c, http.StatusBadRequest, errors.New("请先调用 enroll 生成密钥"))
+		return
+	}
+	step, ok := util.ValidateTOTP(user.TOTPSecret, strings.TrimSpace(req.Code), time.Now(), 1)
+	if !ok || !res.MarkTOTPUsed(user.UUID, step) {
+		res.Error(c, http.StatusBadRequest, errors.New("动态码错误"))
+		return
+	}
+
+	var codes []string
+	err = db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(user).Update("totp_enabled", true).Error; err != nil {
+			return err
+		}
+		var err error
+		codes, err = replaceRecoveryCodes(tx, user.ID)
+		return err
+	})
+	if err != nil {
+		res.Error(c, http.StatusInternalServerError, err)
+		return
+	}
+	res.Success(c, map[string]interface{}{
+		"recovery_codes": codes,
+	})
+}
+
+// MFADisable 关闭两步验证
+// @Summary 关闭两步验证
+// @Description 提交动态码或恢复码关闭两步验证
+// @Tags 两步验证
+// @Accept json
+// @Produce json
+// @Param   Authorization  header  string  true  "Bearer Token"
+// @Param request body model.MFACodeRequest true "动态码或恢复码"
+// @Success 200 {object} middleware.Response "成功"
+// @Router /api/user/mfa/disable [post]
+func MFADisable(c *gin.Context) {
+	var req model.MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		res.Error(c, http.StatusBadRequest, err)
+		return
+	}
+	user, err := actingUser(c)
+	if err != nil {
+		res.Error(c, http.StatusUnauthorized, err)
+		return
+	}
+	if !user.TOTPEnabled {
+		res.Error(c, http.StatusBadRequest, errors.New("未开启两步验证"))
+		return
+	}
+	if !verifyMFACode(user, req.Code) {
+		res.Error(c, http.StatusBadRequest, errors.New("动态码错误"))
+		return
+	}
+	err = db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(user).Updates(map[string]interface{}{"totp_enabled": false, "totp_secret": ""}).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", user.ID).Delete(&model.RecoveryCode{}).Error
+	})
+	if err != nil {
+		res.Error(c, http.StatusInternalServerError, err)
+		return
+	}
+	res.Success(c, "两步验证已关闭")
+}
+
+// LoginMFA 两步验证登录
+// @Summary 两步验证登录
+// @Description 使用登录返回的 mfa_token 加动态码或恢复码换取令牌，mfa_token 提交一次即作废，动态码错误需重新登录
+// @Tags 登录
+// @Accept json
+// @Produce json
+// @Param request body model.MFALoginRequest true "临时令牌与动态码"
+// @Success 200 {object} middleware.Response "成功"
+// @Failure 423 {object} middleware.Response "多次失败被临时锁定 (code=42301)"
+// @Failure 429 {object} middleware.Response "失败后退避中 (code=42901)"
+// @Router /api/user/login/mfa [post]
+func LoginMFA(c *gin.Context) {
+	var req model.MFALoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		res.Error(c, http.StatusBadRequest, err)
+		return
+	}
+	claims, err := res.ParseMFAToken(req.MFAToken)
+	if err != nil {
+		res.Error(c, http.StatusUnauthorized, err)
+		return
+	}
+	// 动态码同样受登录失败次数限制
+	identifier, ip := "mfa:"+claims.UserID, c.ClientIP()
+	if err = res.CheckLoginAllowed(identifier, ip); err != nil {
+		loginBlocked(c, err)
+		return
+	}
+	var user model.User
+	if err = db.DB.Where("uuid = ?", claims.UserID).First(&user).Error; err != nil {
+		res.Error(c, http.StatusUnauthorized, errors.New("用户不存在"))
+		return
+	}
+	// 先作废临时令牌再校验：并发重放同一令牌时只有一个请求能继续，恢复码不会被白白用掉；
+	// 动态码错误时令牌也已作废，需要重新登录
+	if !res.ConsumeMFAToken(claims) {
+		res.Error(c, http.StatusUnauthorized, errors.New("两步验证令牌已使用"))
+		return
+	}
+	if !user.TOTPEnabled || !verifyMFACode(&user, req.Code) {
+		res.RecordLoginFailure(identifier, ip)
+		res.RecordAuthEventFromContext(c, res.AuthEventLoginFailure, claims.UserID, "动态码错误")
+		res.Error(c, http.StatusBadRequest, errors.New("动态码错误，请重新登录"))
+		return
+	}
+	res.ResetLoginFailures(identifier, ip)
+	if err = checkAccountStatus(&user); err != nil {
+		res.RecordAuthEventFromContext(c, res.AuthEventLoginFailure, user.UUID, err.Error())
+		res.ErrorWithCode(c, http.StatusForbidden, res.CodeAccountDisabled, err)
+		return
+	}
+	device := loginDevice(c)
+	issueLogin(c, &user, device, assessLogin(&user, device))
+}
+
+// verifyMFACode 校验 TOTP 动态码（防重放），不是 6 位数字时按恢复码处理
+func verifyMFACode(user *model.User, code string) bool {
+	code = strings.TrimSpace(code)
+	if len(code) == util.TOTPDigits {
+		step, ok := util.ValidateTOTP(user.TOTPSecret, code, time.Now(), 1)
+		return ok && res.MarkTOTPUsed(user.UUID, step)
+	}
+	result := db.DB.Model(&model.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", user.ID, hashRecoveryCode(code)).
+		Update("used_at", time.Now())
+	return result.Error == nil && result.RowsAffected == 1
+}
+
+// replaceRecoveryCodes 生成新的恢复码并替换旧的，返回明文
+func replaceRecoveryCodes(tx *gorm.DB, userID uint) ([]string, error) {
+	if err := tx.Where("user_id = ?", userID).Delete(&model.RecoveryCode{}).Error; err != nil {
+		return nil, err
+	}
+	codes := make([]string, recoveryCodeCount)
+	rows := make([]model.RecoveryCode, recoveryCodeCount)
+	for i := range codes {
+		buf := make([]byte, 7)
+		if _, err := rand.Read(buf); err != nil {
+			return nil, err
+		}
+		raw := strings.ToLower(base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(buf))[:10]
+		codes[i] = raw[:5] + "-" + raw[5:]
+		rows[i] = model.RecoveryCode{UserID: userID, CodeHash: hashRecoveryCode(codes[i])}
+	}
+	if err := tx.Create(&rows).Error; err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// hashRecoveryCode 恢复码忽略大小写与连字符
+func hashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
+
+// mfaIssuer 认证器 App 中显示的发行方
+func mfaIssuer() string {
+	if db.Conf.JWT.Issuer != "" {
+		return db.Conf.JWT.Issuer
+	}
+	return "TestGin"
+}
@@ -0,0 +1,337 @@
+package api
+
+import (
+	db "TestGin/config"
+	res "TestGin/middleware"
+	"TestGin/model"
+	"TestGin/util"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 授权请求的 state 有效期
+const oidcStateExpiration = 10 * time.Minute
+
+// errIdentityConflict 身份无法关联到账号
+var errIdentityConflict = errors.New("该邮箱已被未验证的账号占用，请先用密码登录并验证邮箱后再关联")
+
+// oidcState 发起授权时保存的状态，回调时取出，只能使用一次
+type oidcState struct {
+	Provider string `json:"provider"`
+	Nonce    string `json:"nonce"`
+	Verifier string `json:"verifier"`
+	LinkUser string `json:"link_user,omitempty"` // 已登录用户关联新身份时的 UUID
+	Binding  string `json:"binding"`             // 发起授权的浏览器 Cookie 哈希，回调必须来自同一浏览器
+}
+
+func oidcStateKey(state string) string {
+	return fmt.Sprintf("oidc_state:%s", state)
+}
+
+// OIDCLogin 跳转到身份提供方登录
+// @Summary 第三方登录
+// @Description 跳转到身份提供方（OIDC 授权码 + PKCE），登录后回调 /api/user/oidc/{provider}/callback
+// @Tags 第三方登录
+// @Param provider path string true "身份提供方名称"
+// @Success 302 {string} string "跳转到身份提供方"
+// @Router /api/user/oidc/{provider}/login [get]
+func OIDCLogin(c *gin.Context) {
+	authURL, err := beginOIDC(c, c.Param("provider"), "")
+	if err != nil {
+		res.Error(c, http.StatusBadRequest, err)
+		return
+	}
+	c.Redirect(http.StatusFound, authURL)
+}
+
+// OIDCCallback 身份提供方回调
+// @Summary 第三方登录回调
+// @Description 校验 state、用授权码换取令牌并验证 ID Token；按身份或已验证邮箱关联用户，没有则创建，然后签发令牌
+// @Tags 第三方登录
+// @Produce json
+// @Param provider path string true "身份提供方名称"
+// @Param code query string true "授权码"
+// @Param state query string true "state"
+// @Success 200 {object} middleware.Response "成功"
+// @Failure 409 {object} middleware.Response "邮箱冲突"
+// @Router /api/user/oidc/{provider}/callback [get]
+func OIDCCallback(c *gin.Context) {
+	name := c.Param("provider")
+	if e := c.Query("error"); e != "" {
+		res.Error(c, http.StatusBadRequest, fmt.Errorf("身份提供方返回错误: %s %s", e, c.Query("error_description")))
+		return
+	}
+	data, err := res.GetSessionStore().GetDel(oidcStateKey(c.Query("state")))
+	if err != nil {
+		res.Error(c, http.StatusBadRequest, errors.New("state 无效或已过期"))
+		return
+	}
+	var state oidcState
+	if err = json.Unmarshal([]byte(data), &state); err != nil || state.Provider != name {
+		res.Error(c, http.StatusBadRequest, errors.New("state 无效"))
+		return
+	}
+	// 回调必须来自发起授权的浏览器，否则可能是他人构造的链接（登录 CSRF 或把身份关联到他人账号）
+	if !res.CheckOIDCBindingCookie(c, state.Binding) {
+		res.Error(c, http.StatusBadRequest, errors.New("登录请求不是由当前浏览器发起，请重新登录"))
+		return
+	}
+	provider, pc, ok := db.GetOIDCProvider(name)
+	if !ok {
+		res.Error(c, http.StatusNotFound, errors.New("未知的身份提供方"))
+		return
+	}
+	tokens, err := provider.Exchange(c.Query("code"), state.Verifier)
+	if err != nil {
+		res.Error(c, http.StatusUnauthorized, err)
+		return
+	}
+	claims, err := provider.VerifyIDToken(tokens.IDToken, state.Nonce)
+	if err != nil {
+		res.Error(c, http.StatusUnauthorized, err)
+		return
+	}
+
+	user, err := resolveOIDCUser(pc, claims, state.LinkUser)
+	if errors.Is(err, errIdentityConflict) {
+		res.Error(c, http.StatusConflict, err)
+		return
+	}
+	if err != nil {
+		res.Error(c, http.StatusForbidden, err)
+		return
+	}
+	if state.LinkUser != "" {
+		res.Success(c, "关联成功")
+		return
+	}
+	completeLogin(c, user)
+}
+
+// LinkIdentity 为当前用户关联新的身份提供方
+// @Summary 关联第三方身份
+// @Description 返回身份提供方授权地址，客户端跳转后在回调中完成关联
+// @Tags 第三方登录
+// @Produce json
+// @Param   Authorization  header  string  true  "Bearer Token"
+// @Param provider path string true "身份提供方名称"
+// @Success 200 {object} middleware.Response "authorization_url"
+// @Router /api/user/identities/{provider} [post]
+func LinkIdentity(c *gin.Context) {
+	authURL, err := beginOIDC(c, c.Param("provider"), c.GetString("userID"))
+	if err != nil {
+		res.Error(c, http.StatusBadRequest, err)
+		return
+	}
+	res.Success(c, map[string]string{"authorization_url": authURL})
+}
+
+// ListIdentities 当前用户关联的第三方身份
+// @Summary 第三方身份列表
+// @Tags 第三方登录
+// @Produce json
+// @Param   Authorization  header  string  true  "Bearer Token"
+// @Success 200 {array} model.UserIdentity "身份列表"
+// @Router /api/user/identities [get]
+func ListIdentities(c *gin.Context) {
+	user, err := actingUser(c)
+	if err != nil {
+		res.Error(c, http.StatusUnauthorized, err)
+		return
+	}
+	var identities []model.UserIdentity
+	if err = db.DB.Where("user_id = ?", user.ID).Find(&identities).Error; err != nil {
+		res.Error(c, http.StatusInternalServerError, err)
+		return
+	}
+	res.Success(c, identities)
+}
+
+// UnlinkIdentity 解除第三方身份关联
+// @Summary 解除第三方身份
+// @Tags 第三方登录
+// @Produce json
+// @Param   Authorization  header  string  true  "Bearer Token"
+// @Param id path int true "身份ID"
+// @Success 200 {object} middleware.Response "成功"
+// @Router /api/user/identities/{id} [delete]
+func UnlinkIdentity(c *gin.Context) {
+	user, err := actingUser(c)
+	if err != nil {
+		res.Error(c, http.StatusUnauthorized, err)
+		return
+	}
+	id, _ := strconv.Atoi(c.Param("id"))
+	result := db.DB.Where("id = ? AND user_id = ?", id, user.ID).Delete(&model.UserIdentity{})
+	if result.Error != nil {
+		res.Error(c, http.StatusInternalServerError, result.Error)
+		return
+	}
+	if result.RowsAffected == 0 {
+		res.Error(c, http.StatusNotFound, errors.New("身份不存在"))
+		return
+	}
+	res.Success(c, "已解除关联")
+}
+
+// beginOIDC 生成 state、nonce、PKCE 并保存，同时在浏览器中写入绑定 Cookie，返回授权地址
+func beginOIDC(c *gin.Context, name, linkUser string) (string, error) {
+	provider, _, ok := db.GetOIDCProvider(name)
+	if !ok {
+		return "", errors.New("未知的身份提供方")
+	}
+	verifier, challenge, err := util.GeneratePKCE()
+	if err != nil {
+		return "", err
+	}
+	state, err := util.RandomToken()
+	if err != nil {
+		return "", err
+	}
+	nonce, err := util.RandomToken()
+	if err != nil {
+		return "", err
+	}
+	authURL, err := provider.AuthCodeURL(state, nonce, challenge)
+	if err != nil {
+		return "", err
+	}
+	binding, err := res.SetOIDCBindingCookie(c, int(oidcStateExpiration.Seconds()))
+	if err != nil {
+		return "", err
+	}
+	data, _ := json.Marshal(oidcState{Provider: name, Nonce: nonce, Verifier: verifier, LinkUser: linkUser, Binding: binding})
+	if err = res.GetSessionStore().Set(oidcStateKey(state), string(data), oidcStateExpiration); err != nil {
+		return "", err
+	}
+	return authURL, nil
+}
+
+// resolveOIDCUser 找到或创建身份对应的用户：
+// 已关联的身份直接登录；否则按提供方已验证的邮箱关联已验证邮箱的本地用户；都没有则按配置创建新用户
+func resolveOIDCUser(pc *db.OIDCProviderConfig, claims *util.OIDCClaims, linkUser string) (*model.User, error) {
+	now := time.Now()
+	var identity model.UserIdentity
+	err := db.DB.Where("provider = ? AND subject = ?", pc.Name, claims.Subject).First(&identity).Error
+	if err == nil {
+		var user model.User
+		if err = db.DB.First(&user, identity.UserID).Error; err != nil {
+			return nil, errors.New("用户不存在")
+		}
+		if linkUser != "" && linkUser != user.UUID {
+			return nil, errors.New("该身份已关联其他账号")
+		}
+		db.DB.Model(&identity).Update("last_login_at", now)
+		return &user, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	var user model.User
+	email := model.NormalizeEmail(claims.Email)
+	switch {
+	case linkUser != "":
+		// 已登录用户主动关联
+		if err = db.DB.Where("uuid = ?", linkUser).First(&user).Error; err != nil {
+			return nil, errors.New("用户不存在")
+		}
+	case email != "" && claims.EmailVerified && db.DB.Where("email = ?", email).First(&user).Error == nil:
+		// 本地邮箱未验证时不能关联，避免他人预先用该邮箱注册后劫持第三方登录
+		if !user.EmailVerified {
+			return nil, errIdentityConflict
+		}
+	case !pc.AutoCreate:
+		return nil, errors.New("没有与该身份关联的账号")
+	case registerMode() != RegisterModeOpen:
+		// 第三方登录无法提交邀请码，邀请制与暂停注册时不自动创建账号
+		return nil, errors.New("当前未开放注册，没有与该身份关联的账号")
+	case email == "" || !claims.EmailVerified:
+		return nil, errors.New("身份提供方未返回已验证的邮箱")
+	default:
+		created, err := createOIDCUser(pc.Name, claims, email)
+		if err != nil {
+			return nil, err
+		}
+		user = *created
+	}
+
+	identity = model.UserIdentity{
+		UserID:      user.ID,
+		Provider:    pc.Name,
+		Subject:     claims.Subject,
+		Email:       email,
+		LastLoginAt: &now,
+	}
+	if err = db.DB.Create(&identity).Error; err != nil {
+		return nil, err
+	}
+	log.Printf("🔗 用户 %s 关联了 %s 身份", user.UUID, pc.Name)
+	return &user, nil
+}
+
+// createOIDCUser 创建第三方登录用户，密码为随机值，可通过重置密码设置
+func createOIDCUser(provider string, claims *util.OIDCClaims, email string) (*model.User, error) {
+	password, err := util.RandomToken()
+	if err != nil {
+		return nil, err
+	}
+	username, err := uniqueUsername(claims.PreferredUsername, claims.Name, strings.Split(email, "@")[0])
+	if err != nil {
+		return nil, err
+	}
+	user := model.User{
+		Account:       provider + ":" + claims.Subject,
+		Username:      username,
+		Password:      password,
+		Email:         email,
+		Status:        "active",
+		EmailVerified: true,
+	}
+	if len(user.Account) > 100 {
+		user.Account = user.Account[:100]
+	}
+	if err = db.DB.Create(&user).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// uniqueUsername 从候选名称中选出未被占用的用户名，都被占用时追加随机后缀
+func uniqueUsername(candidates ...string) (string, error) {
+	base := "user"
+	for _, name := range candidates {
+		if name = strings.TrimSpace(name); name != "" {
+			base = name
+			break
+		}
+	}
+	if r := []rune(base); len(r) > 15 {
+		base = string(r[:15])
+	}
+	name := base
+	for i := 0; i < 5; i++ {
+		var count int64
+		// 唯一索引包含已软删除的用户
+		if err := db.DB.Unscoped().Model(&model.User{}).Where("username = ?", name).Count(&count).Error; err != nil {
+			return "", err
+		}
+		if count == 0 {
+			return name, nil
+		}
+		suffix, err := util.RandomToken()
+		if err != nil {
+			return "", err
+		}
+		name = base + "_" + strings.ToLower(suffix[:4])
+	}
+	return "", errors.New("无法生成唯一的用户名")
+}
@@ -0,0 +1,50 @@
+package api
+
+import (
+	db "TestGin/config"
+	res "TestGin/middleware"
+	"TestGin/model"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// errNotOwner 不是资源所有者
+var errNotOwner = errors.New("无权操作他人的资源")
+
+// actingUser 获取当前登录用户，身份只取 JWTAuthMiddleware 写入上下文的 userID，不信任请求体
+func actingUser(c *gin.Context) (*model.User, error) {
+	uuid := c.GetString("userID")
+	if uuid == "" {
+		return nil, errors.New("未登录")
+	}
+	var user model.User
+	if err := db.DB.Where("uuid = ?", uuid).First(&user).Error; err != nil {
+		return nil, errors.New("用户不存在")
+	}
+	return &user, nil
+}
+
+// checkOwner 校验资源归属，非本人且角色没有 moderatePermission 时拒绝
+func checkOwner(c *gin.Context, ownerID uint, moderatePermission string) error {
+	user, err := actingUser(c)
+	if err != nil {
+		return err
+	}
+	if user.ID == ownerID {
+		return nil
+	}
+	if res.HasPermission(c.GetString("role"), moderatePermission) {
+		return nil
+	}
+	return errNotOwner
+}
+
+// ownershipError 将归属校验错误转换为响应
+func ownershipError(c *gin.Context, err error) {
+	if errors.Is(err, errNotOwner) {
+		res.Error(c, http.StatusForbidden, err)
+		return
+	}
+	res.Error(c, http.StatusUnauthorized, err)
+}
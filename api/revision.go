@@ -0,0 +1,245 @@
+package api
+
+import (
+	db "TestGin/config"
+	res "TestGin/middleware"
+	"TestGin/model"
+	"TestGin/util"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"net/http"
+	"strconv"
+)
+
+var errRevisionNotFound = errors.New("版本不存在或已超出保留数量被清理")
+
+// saveRevision 为文章当前的标题与内容生成新版本，并按 article.revisionretention 清理旧版本，需在事务中调用。
+// 文章行加锁，并发修改时版本号不会重复
+func saveRevision(tx *gorm.DB, article *model.Article, authorID uint, restoredFrom *int) (*model.ArticleRevision, error) {
+	if err := lockArticle(tx, article.ID); err != nil {
+		return nil, err
+	}
+	var latest int
+	err := tx.Model(&model.ArticleRevision{}).Where("article_id = ?", article.ID).
+		Select("COALESCE(MAX(version), 0)").Scan(&latest).Error
+	if err != nil {
+		return nil, err
+	}
+	revision := model.ArticleRevision{
+		ArticleID:    article.ID,
+		Version:      latest + 1,
+		Title:        article.Title,
+		Content:      article.Content,
+		AuthorID:     authorID,
+		RestoredFrom: restoredFrom,
+	}
+	if err = tx.Create(&revision).Error; err != nil {
+		return nil, err
+	}
+	if keep := db.Conf.Article.RevisionRetention; keep > 0 {
+		err = tx.Where("article_id = ? AND version <= ?", article.ID, revision.Version-keep).
+			Delete(&model.ArticleRevision{}).Error
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &revision, nil
+}
+
+// ensureBaseRevision 修订功能上线前创建的文章没有版本记录，第一次修改前先把原内容保存为版本 1
+func ensureBaseRevision(tx *gorm.DB, article *model.Article) error {
+	if err := lockArticle(tx, article.ID); err != nil {
+		return err
+	}
+	var count int64
+	if err := tx.Model(&model.ArticleRevision{}).Where("article_id = ?", article.ID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+	_, err := saveRevision(tx, article, uint(article.UserID), nil)
+	return err
+}
+
+// findRevision 查询文章的某个版本
+func findRevision(tx *gorm.DB, articleID int64, version int) (*model.ArticleRevision, error) {
+	var revision model.ArticleRevision
+	err := tx.Where("article_id = ? AND version = ?", articleID, version).First(&revision).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errRevisionNotFound
+	}
+	return &revision, err
+}
+
+// ownArticle 读取路径中的文章并校验归属，失败时已写入响应
+func ownArticle(c *gin.Context) (*model.Article, bool) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	var article model.Article
+	if err := db.DB.First(&article, id).Error; err != nil {
+		res.Error(c, http.StatusNotFound, errors.New("文章不存在"))
+		return nil, false
+	}
+	if err := checkOwner(c, uint(article.UserID), "article:moderate"); err != nil {
+		ownershipError(c, err)
+		return nil, false
+	}
+	return &article, true
+}
+
+// revisionError 将版本查询错误转换为响应
+func revisionError(c *gin.Context, err error) {
+	if errors.Is(err, errRevisionNotFound) {
+		res.Error(c, http.StatusNotFound, err)
+		return
+	}
+	res.Error(c, http.StatusInternalServerError, err)
+}
+
+// ListRevisions 文章修订记录
+// @Summary 文章修订记录
+// @Description 作者本人或拥有 article:moderate 权限的角色查看，按版本倒序，不含正文
+// @Tags 文章修订
+// @Produce json
+// @Param   Authorization  header  string  true  "Bearer Token"
+// @Param id path int true "文章ID"
+// @Success 200 {array} model.ArticleRevision "版本列表"
+// @Failure 403 {object} middleware.Response "不是文章作者"
+// @Router /api/article/{id}/revisions [get]
+func ListRevisions(c *gin.Context) {
+	article, ok := ownArticle(c)
+	if !ok {
+		return
+	}
+	var revisions []model.ArticleRevision
+	err := db.DB.Omit("content").Where("article_id = ?", article.ID).Order("version DESC").Find(&revisions).Error
+	if err != nil {
+		res.Error(c, http.StatusInternalServerError, err)
+		return
+	}
+	res.Success(c, revisions)
+}
+
+// GetRevision 查看某个版本
+// @Summary 查看文章版本
+// @Tags 文章修订
+// @Produce json
+// @Param   Authorization  header  string  true  "Bearer Token"
+// @Param id path int true "文章ID"
+// @Param version path int true "版本号"
+// @Success 200 {object} model.ArticleRevision "版本内容"
+// @Failure 404 {object} middleware.Response "版本不存在"
+// @Router /api/article/{id}/revisions/{version} [get]
+func GetRevision(c *gin.Context) {
+	article, ok := ownArticle(c)
+	if !ok {
+		return
+	}
+	version, _ := strconv.Atoi(c.Param("version"))
+	revision, err := findRevision(db.DB, article.ID, version)
+	if err != nil {
+		revisionError(c, err)
+		return
+	}
+	res.Success(c, revision)
+}
+
+// DiffRevisions 比较两个版本
+// @Summary 比较文章版本
+// @Description mode=unified 返回按行比较的统一差异（diff -u 格式）；mode=word 返回逐词差异片段，中文按字比较。标题始终逐词比较
+// @Tags 文章修订
+// @Produce json
+// @Param   Authorization  header  string  true  "Bearer Token"
+// @Param id path int true "文章ID"
+// @Param from query int true "旧版本号"
+// @Param to query int true "新版本号"
+// @Param mode query string false "unified（默认）或 word"
+// @Success 200 {object} model.RevisionDiff "差异"
+// @Failure 404 {object} middleware.Response "版本不存在"
+// @Router /api/article/{id}/revisions/diff [get]
+func DiffRevisions(c *gin.Context) {
+	var q model.RevisionDiffQuery
+	if err := c.ShouldBindQuery(&q); err != nil {
+		res.Error(c, http.StatusBadRequest, err)
+		return
+	}
+	if q.Mode == "" {
+		q.Mode = "unified"
+	}
+	article, ok := ownArticle(c)
+	if !ok {
+		return
+	}
+	from, err := findRevision(db.DB, article.ID, q.From)
+	if err != nil {
+		revisionError(c, err)
+		return
+	}
+	to, err := findRevision(db.DB, article.ID, q.To)
+	if err != nil {
+		revisionError(c, err)
+		return
+	}
+	diff := model.RevisionDiff{
+		From:  from.Version,
+		To:    to.Version,
+		Mode:  q.Mode,
+		Title: util.DiffWords(from.Title, to.Title),
+	}
+	if q.Mode == "word" {
+		diff.Content = util.DiffWords(from.Content, to.Content)
+	} else {
+		diff.Unified = util.UnifiedDiff(from.Content, to.Content, fmt.Sprintf("v%d", from.Version), fmt.Sprintf("v%d", to.Version), 3)
+	}
+	res.Success(c, diff)
+}
+
+// RestoreRevision 恢复到某个版本
+// @Summary 恢复文章版本
+// @Description 用旧版本的标题与内容覆盖文章，并生成一个新版本（restored_from 为来源版本），不会删除之后的版本
+// @Tags 文章修订
+// @Produce json
+// @Param   Authorization  header  string  true  "Bearer Token"
+// @Param id path int true "文章ID"
+// @Param version path int true "要恢复的版本号"
+// @Success 200 {object} model.ArticleRevision "新生成的版本"
+// @Failure 404 {object} middleware.Response "版本不存在"
+// @Router /api/article/{id}/revisions/{version}/restore [post]
+func RestoreRevision(c *gin.Context) {
+	article, ok := ownArticle(c)
+	if !ok {
+		return
+	}
+	user, err := actingUser(c)
+	if err != nil {
+		res.Error(c, http.StatusUnauthorized, err)
+		return
+	}
+	version, _ := strconv.Atoi(c.Param("version"))
+	var revision *model.ArticleRevision
+	err = db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := ensureBaseRevision(tx, article); err != nil {
+			return err
+		}
+		source, err := findRevision(tx, article.ID, version)
+		if err != nil {
+			return err
+		}
+		article.Title, article.Content = source.Title, source.Content
+		err = tx.Model(article).Updates(map[string]interface{}{"title": article.Title, "content": article.Content}).Error
+		if err != nil {
+			return err
+		}
+		revision, err = saveRevision(tx, article, user.ID, &source.Version)
+		return err
+	})
+	if err != nil {
+		revisionError(c, err)
+		return
+	}
+	invalidateArticleCache(article.ID)
+	indexArticle(*article)
+	res.Success(c, revision)
+}
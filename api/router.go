@@ -4,7 +4,6 @@ import (
 	"TestGin/config"
 	_ "TestGin/docs"
 	"TestGin/middleware"
-	"TestGin/util"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -15,36 +14,110 @@ import (
 // RegisterRoutes 注册所有API路由
 func RegisterRoutes(r *gin.Engine) {
 	red := config.GetRedisClient()
+	middleware.SetPATAuthenticator(AuthenticatePAT)
+	middleware.SetAuthEventRecorder(saveAuthEvent)
 	// Swagger文档
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 	r.Static("/static", "./static")
+	// 公钥集合，供其他服务验证访问令牌
+	r.GET("/.well-known/jwks.json", JWKS)
 
-	// API CURD 分组
+	// API CURD 分组，登录、刷新、注册之外的接口都需要认证
 	v1 := r.Group("/api")
-	//v1.Use(middleware.JWTAuthMiddleware())
-	// WebSocket 路由
-	r.GET("/ws", util.HandleWebsocket)
+	v1.Use(middleware.JWTAuthMiddleware())
+	// WebSocket 路由：util/wsTest.go 中的处理函数已注释，恢复后再启用
+	//r.GET("/ws", util.HandleWebsocket)
 	article := v1.Group("/article")
 	{
-		article.POST("/add", AddArticle)
-		article.PUT("/update/:id", UpdateArticle)
+		article.POST("/add", middleware.RequirePermission("article:create"), AddArticle)
+		article.PUT("/update/:id", middleware.RequirePermission("article:update"), UpdateArticle)
 		//更新文章状态
-		article.PUT("/:uuid/status", UpdateArticleStatus)
-		article.DELETE("/delete/:id", DeleteArticle)
-		//article.GET("/list", ListArticle)
-		article.GET("/get/:id", middleware.RedisCacheMiddleware(middleware.CacheOptions{RedisClient: red, TTL: 60 * time.Second}, GetArticle))
+		// 具体能执行哪些流转由状态机按作者与角色权限判断
+		article.PUT("/:id/status", middleware.RequirePermission("article:status"), UpdateArticleStatus)
+		article.GET("/:id/history", middleware.RequirePermission("article:read"), ListArticleStatusHistory)
+		article.GET("/:id/revisions", middleware.RequirePermission("article:read"), ListRevisions)
+		article.GET("/:id/revisions/diff", middleware.RequirePermission("article:read"), DiffRevisions)
+		article.GET("/:id/revisions/:version", middleware.RequirePermission("article:read"), GetRevision)
+		article.POST("/:id/revisions/:version/restore", middleware.RequirePermission("article:update"), RestoreRevision)
+		article.DELETE("/delete/:id", middleware.RequirePermission("article:delete"), DeleteArticle)
+		article.GET("/list", middleware.RequirePermission("article:read"), middleware.RedisCacheMiddleware(middleware.CacheOptions{RedisClient: red, TTL: 30 * time.Second}, ListArticle))
+		article.GET("/search", middleware.RequirePermission("article:read"), middleware.RedisCacheMiddleware(middleware.CacheOptions{RedisClient: red, TTL: 30 * time.Second}, SearchArticles))
+		article.GET("/tags", middleware.RequirePermission("article:read"), middleware.RedisCacheMiddleware(middleware.CacheOptions{RedisClient: red, TTL: 60 * time.Second}, ListTags))
+		article.PUT("/tags/:id", middleware.RequirePermission("article:taxonomy"), RenameTag)
+		article.POST("/tags/merge", middleware.RequirePermission("article:taxonomy"), MergeTags)
+		article.GET("/categories", middleware.RequirePermission("article:read"), ListCategories)
+		article.POST("/categories", middleware.RequirePermission("article:taxonomy"), CreateCategory)
+		article.PUT("/categories/:id", middleware.RequirePermission("article:taxonomy"), UpdateCategory)
+		article.DELETE("/categories/:id", middleware.RequirePermission("article:taxonomy"), DeleteCategory)
+		article.GET("/get/:id", middleware.RequirePermission("article:read"), countArticleView, middleware.RedisCacheMiddleware(middleware.CacheOptions{RedisClient: red, TTL: 60 * time.Second, KeyFunc: articleCacheKey}, GetArticle))
 	}
 	user := v1.Group("/user")
 	{
 		user.POST("/add", Register)
-		user.POST("/refresh", RefreshToken)
-		user.GET("/login", Login)
-		user.GET("/list", middleware.RedisCacheMiddleware(middleware.CacheOptions{RedisClient: red, TTL: 60 * time.Second}, ListUsers))
-		user.GET("/get/:id", middleware.RedisCacheMiddleware(middleware.CacheOptions{RedisClient: red, TTL: 60 * time.Second}, GetUser))
+		user.POST("/refresh", middleware.CSRFProtect(), RefreshToken)
+		user.POST("/login", Login)
+		if config.Conf.Login.AllowLegacyGet {
+			// 已废弃，保留一个版本
+			user.GET("/login", LoginLegacy)
+		}
+		user.POST("/login/mfa", LoginMFA)
+		user.POST("/login/step-up", LoginStepUp)
+		// 第三方登录 (OIDC)
+		user.GET("/oidc/:provider/login", OIDCLogin)
+		user.GET("/oidc/:provider/callback", OIDCCallback)
+		identities := user.Group("/identities", middleware.RequireSession())
+		{
+			identities.GET("", ListIdentities)
+			identities.POST("/:provider", LinkIdentity)
+			identities.DELETE("/:id", UnlinkIdentity)
+		}
+		user.POST("/logout", middleware.RequireSession(), Logout)
+		user.GET("/list", middleware.RequirePermission("user:list"), middleware.RedisCacheMiddleware(middleware.CacheOptions{RedisClient: red, TTL: 60 * time.Second}, ListUsers))
+		user.GET("/get/:id", middleware.RequirePermission("user:read"), middleware.RedisCacheMiddleware(middleware.CacheOptions{RedisClient: red, TTL: 60 * time.Second}, GetUser))
+		user.POST("/disable/:id", middleware.RequirePermission("user:disable"), DisableUser)
+		user.POST("/enable/:id", middleware.RequirePermission("user:disable"), EnableUser)
+		user.POST("/role/:id", middleware.RequirePermission("user:role"), UpdateUserRole)
+		user.POST("/impersonate/:id", middleware.RequireSession(), middleware.RequirePermission("user:impersonate"), ImpersonateUser)
+		user.GET("/auth-events", middleware.RequireSession(), ListMyAuthEvents)
+		sessions := user.Group("/sessions", middleware.RequireSession())
+		{
+			sessions.GET("", ListSessions)
+			sessions.DELETE("/others", RevokeOtherSessions)
+			sessions.DELETE("/:id", RevokeSession)
+		}
+		password := user.Group("/password")
+		{
+			password.POST("/forgot", ForgotPassword)
+			password.POST("/reset", ResetPassword)
+		}
+		email := user.Group("/email")
+		{
+			email.POST("/verify", VerifyEmail)
+			email.POST("/resend", middleware.DenyImpersonation(), middleware.RequirePermission("user:update"), ResendVerification)
+		}
+		mfa := user.Group("/mfa", middleware.RequireSession())
+		{
+			mfa.POST("/enroll", MFAEnroll)
+			mfa.POST("/confirm", MFAConfirm)
+			mfa.POST("/disable", MFADisable)
+		}
+		invites := user.Group("/invites", middleware.RequirePermission("invite:create"))
+		{
+			invites.POST("", CreateInvite)
+			invites.GET("", ListInvites)
+			invites.DELETE("/:id", RevokeInvite)
+		}
+		// 个人访问令牌只能通过登录会话管理
+		tokens := user.Group("/tokens", middleware.RequireSession())
+		{
+			tokens.POST("", CreateToken)
+			tokens.GET("", ListTokens)
+			tokens.DELETE("/:id", RevokeToken)
+		}
 		update := user.Group("/update")
 		{
-			update.POST("/password", UpdatePassword)
-			update.POST("/user", UpdateUser)
+			update.POST("/password", middleware.RequireSession(), UpdatePassword)
+			update.POST("/user", middleware.DenyImpersonation(), middleware.RequirePermission("user:update"), UpdateUser)
 		}
 	}
 	//file := v1.Group("/upload")
@@ -53,8 +126,12 @@ func RegisterRoutes(r *gin.Engine) {
 	//}
 	comment := v1.Group("/comment")
 	{
-		comment.POST("/add", AddComment)
-		comment.GET("/list", middleware.RedisCacheMiddleware(middleware.CacheOptions{RedisClient: red, TTL: 60 * time.Second}, ListComments))
+		comment.POST("/add", middleware.RequirePermission("comment:create"), AddComment)
+		comment.GET("/list", middleware.RequirePermission("comment:read"), middleware.RedisCacheMiddleware(middleware.CacheOptions{RedisClient: red, TTL: 60 * time.Second}, ListComments))
+	}
+	audit := v1.Group("/audit")
+	{
+		audit.GET("/auth-events", middleware.RequirePermission("audit:read"), ListAuthEvents)
 	}
 
 	// 其他路由
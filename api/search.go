@@ -0,0 +1,67 @@
+package api
+
+import (
+	db "TestGin/config"
+	res "TestGin/middleware"
+	"TestGin/model"
+	"TestGin/search"
+	"github.com/gin-gonic/gin"
+	"log"
+	"net/http"
+)
+
+// indexArticle 文章写入后更新搜索索引，失败只记录日志
+func indexArticle(article model.Article) {
+	if err := db.GetSearcher().Index(db.ArticleDocument(article)); err != nil {
+		log.Printf("更新搜索索引失败: %v", err)
+	}
+}
+
+// removeArticleIndex 文章删除后移出搜索索引
+func removeArticleIndex(id int64) {
+	if err := db.GetSearcher().Delete(id); err != nil {
+		log.Printf("删除搜索索引失败: %v", err)
+	}
+}
+
+// SearchArticles 搜索文章
+// @Summary 搜索文章
+// @Description 在已发布文章的标题与内容中搜索，按相关度排序；标题与摘要中的命中词用 <em></em> 包裹，其余内容已做 HTML 转义
+// @Tags 文章
+// @Produce json
+// @Param   Authorization  header  string  true  "Bearer Token"
+// @Param q query string true "关键词"
+// @Param user_id query int false "作者ID"
+// @Param from query string false "创建时间起 (RFC3339)"
+// @Param to query string false "创建时间止 (RFC3339)"
+// @Param page query int false "页码，默认 1，最多 50"
+// @Param size query int false "每页条数，默认 10，最多 50"
+// @Success 200 {object} search.Result "搜索结果"
+// @Router /api/article/search [get]
+func SearchArticles(c *gin.Context) {
+	var q model.ArticleSearchQuery
+	if err := c.ShouldBindQuery(&q); err != nil {
+		res.Error(c, http.StatusBadRequest, err)
+		return
+	}
+	if q.Page == 0 {
+		q.Page = 1
+	}
+	if q.Size == 0 {
+		q.Size = 10
+	}
+	result, err := db.GetSearcher().Search(search.Query{
+		Text:   q.Q,
+		Status: int(model.Published),
+		UserID: q.UserID,
+		From:   q.From,
+		To:     q.To,
+		Offset: (q.Page - 1) * q.Size,
+		Limit:  q.Size,
+	})
+	if err != nil {
+		res.Error(c, http.StatusInternalServerError, err)
+		return
+	}
+	res.Success(c, result)
+}
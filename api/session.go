@@ -0,0 +1,85 @@
+package api
+
+import (
+	res "TestGin/middleware"
+	ti "TestGin/util"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"time"
+)
+
+// SessionResponse 会话响应
+type SessionResponse struct {
+	ID         string `json:"id"`
+	DeviceName string `json:"device_name"`
+	UserAgent  string `json:"user_agent"`
+	IP         string `json:"ip"`
+	CreatedAt  string `json:"created_at"`
+	LastActive string `json:"last_active"`
+	Current    bool   `json:"current"` // 是否为当前请求所用的会话
+}
+
+// ListSessions 当前用户的登录会话
+// @Summary 登录会话列表
+// @Description 列出当前用户在各设备上的登录会话
+// @Tags 会话
+// @Produce json
+// @Param   Authorization  header  string  true  "Bearer Token"
+// @Success 200 {array} SessionResponse "会话列表"
+// @Router /api/user/sessions [get]
+func ListSessions(c *gin.Context) {
+	sessions, err := res.ListSessions(c.GetString("userID"))
+	if err != nil {
+		res.Error(c, http.StatusInternalServerError, err)
+		return
+	}
+	current := c.GetString("sessionID")
+	list := make([]SessionResponse, len(sessions))
+	for i, s := range sessions {
+		list[i] = SessionResponse{
+			ID:         s.ID,
+			DeviceName: s.DeviceName,
+			UserAgent:  s.UserAgent,
+			IP:         s.IP,
+			CreatedAt:  ti.FormatTime(time.Unix(s.CreatedAt, 0)),
+			LastActive: ti.FormatTime(time.Unix(s.LastActive, 0)),
+			Current:    s.ID == current,
+		}
+	}
+	res.Success(c, list)
+}
+
+// RevokeSession 撤销单个会话
+// @Summary 撤销会话
+// @Description 撤销当前用户的指定会话，该设备需重新登录
+// @Tags 会话
+// @Produce json
+// @Param   Authorization  header  string  true  "Bearer Token"
+// @Param id path string true "会话ID"
+// @Success 200 {object} middleware.Response "成功"
+// @Router /api/user/sessions/{id} [delete]
+func RevokeSession(c *gin.Context) {
+	if err := res.RevokeSession(c.GetString("userID"), c.Param("id")); err != nil {
+		res.Error(c, http.StatusNotFound, err)
+		return
+	}
+	res.RecordAuthEventFromContext(c, res.AuthEventSessionRevoked, c.GetString("userID"), "撤销会话 "+c.Param("id"))
+	res.Success(c, "会话已撤销")
+}
+
+// RevokeOtherSessions 撤销其他会话
+// @Summary 撤销其他会话
+// @Description 撤销除当前会话外的所有会话
+// @Tags 会话
+// @Produce json
+// @Param   Authorization  header  string  true  "Bearer Token"
+// @Success 200 {object} middleware.Response "成功"
+// @Router /api/user/sessions/others [delete]
+func RevokeOtherSessions(c *gin.Context) {
+	if err := res.RevokeOtherSessions(c.GetString("userID"), c.GetString("sessionID")); err != nil {
+		res.Error(c, http.StatusInternalServerError, err)
+		return
+	}
+	res.RecordAuthEventFromContext(c, res.AuthEventSessionRevoked, c.GetString("userID"), "撤销其他会话")
+	res.Success(c, "其他会话已撤销")
+}
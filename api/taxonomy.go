@@ -0,0 +1,593 @@
+package api
+
+import (
+	db "TestGin/config"
+	res "TestGin/middleware"
+	"TestGin/model"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// 管理标签与分类的权限：重命名、合并标签，增删改分类
+const taxonomyPermission = "article:taxonomy"
+
+var (
+	errCategoryNotFound = errors.New("分类不存在")
+	errCategoryName     = errors.New("分类名不能为空")
+	errCategoryExists   = errors.New("同级分类中已有同名分类")
+	errCategoryCycle    = errors.New("不能把分类移动到自己或自己的下级分类下")
+)
+
+// normalizeTagNames 去除首尾空白，忽略空标签，按不区分大小写去重（与 MySQL 默认排序规则一致）
+func normalizeTagNames(names []string) []string {
+	seen := make(map[string]bool, len(names))
+	out := make([]string, 0, len(names))
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		key := strings.ToLower(name)
+		if name == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		out = append(out, name)
+	}
+	return out
+}
+
+// ensureTags 查找或创建标签，返回标签ID；并发创建同名标签时由唯一索引去重
+func ensureTags(tx *gorm.DB, names []string) ([]uint, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+	tags := make([]model.Tag, len(names))
+	for i, name := range names {
+		tags[i].Name = name
+	}
+	if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&tags).Error; err != nil {
+		return nil, err
+	}
+	var ids []uint
+	err := tx.Model(&model.Tag{}).Where("name IN ?", names).Pluck("id", &ids).Error
+	return ids, err
+}
+
+// diffIDs 返回 next 中新增的与 prev 中移除的ID
+func diffIDs(prev, next []uint) (added, removed []uint) {
+	inPrev := make(map[uint]bool, len(prev))
+	for _, id := range prev {
+		inPrev[id] = true
+	}
+	inNext := make(map[uint]bool, len(next))
+	for _, id := range next {
+		inNext[id] = true
+		if !inPrev[id] {
+			added = append(added, id)
+		}
+	}
+	for _, id := range prev {
+		if !inNext[id] {
+			removed = append(removed, id)
+		}
+	}
+	return added, removed
+}
+
+// lockArticle 锁定文章行，同一篇文章的标签与分类修改串行执行，计数不会重复增减
+func lockArticle(tx *gorm.DB, articleID int64) error {
+	return tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&model.Article{}, articleID).Error
+}
+
+// setArticleTags 替换文章的标签并在同一事务中增减标签的文章数，需在事务中调用
+func setArticleTags(tx *gorm.DB, articleID int64, names []string) error {
+	ids, err := ensureTags(tx, normalizeTagNames(names))
+	if err != nil {
+		return err
+	}
+	var prev []uint
+	if err = tx.Model(&model.ArticleTag{}).Where("article_id = ?", articleID).Pluck("tag_id", &prev).Error; err != nil {
+		return err
+	}
+	added, removed := diffIDs(prev, ids)
+	if len(removed) > 0 {
+		if err = tx.Where("article_id = ? AND tag_id IN ?", articleID, removed).Delete(&model.ArticleTag{}).Error; err != nil {
+			return err
+		}
+		if err = tx.Model(&model.Tag{}).Where("id IN ?", removed).
+			UpdateColumn("article_count", gorm.Expr("article_count - 1")).Error; err != nil {
+			return err
+		}
+	}
+	if len(added) > 0 {
+		links := make([]model.ArticleTag, len(added))
+		for i, id := range added {
+			links[i] = model.ArticleTag{ArticleID: articleID, TagID: id}
+		}
+		if err = tx.Create(&links).Error; err != nil {
+			return err
+		}
+		if err = tx.Model(&model.Tag{}).Where("id IN ?", added).
+			UpdateColumn("article_count", gorm.Expr("article_count + 1")).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setArticleCategories 替换文章的分类，分类不存在时返回 errCategoryNotFound，需在事务中调用
+func setArticleCategories(tx *gorm.DB, articleID int64, ids []uint) error {
+	ids = uniqueIDs(ids)
+	if len(ids) > 0 {
+		var count int64
+		if err := tx.Model(&model.Category{}).Where("id IN ?", ids).Count(&count).Error; err != nil {
+			return err
+		}
+		if count != int64(len(ids)) {
+			return errCategoryNotFound
+		}
+	}
+	if err := tx.Where("article_id = ?", articleID).Delete(&model.ArticleCategory{}).Error; err != nil {
+		return err
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	links := make([]model.ArticleCategory, len(ids))
+	for i, id := range ids {
+		links[i] = model.ArticleCategory{ArticleID: articleID, CategoryID: id}
+	}
+	return tx.Create(&links).Error
+}
+
+// saveArticleTaxonomy 按请求更新文章的标签与分类，省略的字段保持不变
+func saveArticleTaxonomy(tx *gorm.DB, articleID int64, req *model.ArticleRequest) error {
+	if req.Tags == nil && req.CategoryIDs == nil {
+		return nil
+	}
+	if err := lockArticle(tx, articleID); err != nil {
+		return err
+	}
+	if req.Tags != nil {
+		if err := setArticleTags(tx, articleID, *req.Tags); err != nil {
+			return err
+		}
+	}
+	if req.CategoryIDs != nil {
+		return setArticleCategories(tx, articleID, *req.CategoryIDs)
+	}
+	return nil
+}
+
+// clearArticleTaxonomy 删除文章时移除标签与分类关联，标签计数同步减少
+func clearArticleTaxonomy(tx *gorm.DB, articleID int64) error {
+	empty := []string{}
+	none := []uint{}
+	return saveArticleTaxonomy(tx, articleID, &model.ArticleRequest{Tags: &empty, CategoryIDs: &none})
+}
+
+// uniqueIDs 去重并忽略 0
+func uniqueIDs(ids []uint) []uint {
+	seen := make(map[uint]bool, len(ids))
+	out := make([]uint, 0, len(ids))
+	for _, id := range ids {
+		if id != 0 && !seen[id] {
+			seen[id] = true
+			out = append(out, id)
+		}
+	}
+	return out
+}
+
+// articleResponses 转换为响应格式，并批量加载标签与分类
+func articleResponses(articles []model.Article) ([]model.ArticleResponse, error) {
+	items := make([]model.ArticleResponse, len(articles))
+	index := make(map[int64]int, len(articles))
+	ids := make([]int64, len(articles))
+	for i, a := range articles {
+		items[i] = model.ArticleToResponse(a)
+		items[i].Tags = []string{}
+		items[i].Categories = []model.Category{}
+		index[a.ID] = i
+		ids[i] = a.ID
+	}
+	if len(ids) == 0 {
+		return items, nil
+	}
+	var tags []struct {
+		ArticleID int64
+		Name      string
+	}
+	err := db.DB.Table("article_tags").Select("article_tags.article_id, tags.name").
+		Joins("JOIN tags ON tags.id = article_tags.tag_id").
+		Where("article_tags.article_id IN ?", ids).Order("tags.name").Scan(&tags).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range tags {
+		i := index[t.ArticleID]
+		items[i].Tags = append(items[i].Tags, t.Name)
+	}
+	var categories []struct {
+		ArticleID int64
+		model.Category
+	}
+	err = db.DB.Table("article_categories").Select("article_categories.article_id, categories.*").
+		Joins("JOIN categories ON categories.id = article_categories.category_id").
+		Where("article_categories.article_id IN ?", ids).Order("categories.sort, categories.id").Scan(&categories).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range categories {
+		i := index[c.ArticleID]
+		items[i].Categories = append(items[i].Categories, c.Category)
+	}
+	return items, nil
+}
+
+// loadCategories 全部分类，按排序值与ID排列；分类数量有限，层级关系在内存中计算
+func loadCategories() ([]model.Category, error) {
+	var categories []model.Category
+	err := db.DB.Order("sort, id").Find(&categories).Error
+	return categories, err
+}
+
+// categoryDescendants 分类及其所有下级分类的ID
+func categoryDescendants(categories []model.Category, id uint) []uint {
+	children := make(map[uint][]uint)
+	for _, c := range categories {
+		if c.ParentID != nil {
+			children[*c.ParentID] = append(children[*c.ParentID], c.ID)
+		}
+	}
+	ids := []uint{id}
+	for i := 0; i < len(ids); i++ {
+		ids = append(ids, children[ids[i]]...)
+	}
+	return ids
+}
+
+// categoryTree 组装分类树
+func categoryTree(categories []model.Category) []*model.CategoryNode {
+	nodes := make(map[uint]*model.CategoryNode, len(categories))
+	for _, c := range categories {
+		nodes[c.ID] = &model.CategoryNode{Category: c, Children: []*model.CategoryNode{}}
+	}
+	roots := []*model.CategoryNode{}
+	for _, c := range categories {
+		if c.ParentID != nil {
+			if parent, ok := nodes[*c.ParentID]; ok {
+				parent.Children = append(parent.Children, nodes[c.ID])
+				continue
+			}
+		}
+		roots = append(roots, nodes[c.ID])
+	}
+	return roots
+}
+
+// derefID 空的上级分类视为 0
+func derefID(id *uint) uint {
+	if id == nil {
+		return 0
+	}
+	return *id
+}
+
+// ListTags 标签云
+// @Summary 标签云
+// @Description 返回至少有一篇文章的标签及文章数，按文章数倒序；文章数包含未发布的文章
+// @Tags 标签与分类
+// @Produce json
+// @Param   Authorization  header  string  true  "Bearer Token"
+// @Param limit query int false "返回数量，默认 100，最多 500"
+// @Success 200 {array} model.Tag "标签列表"
+// @Router /api/article/tags [get]
+func ListTags(c *gin.Context) {
+	var q model.TagCloudQuery
+	if err := c.ShouldBindQuery(&q); err != nil {
+		res.Error(c, http.StatusBadRequest, err)
+		return
+	}
+	if q.Limit == 0 {
+		q.Limit = 100
+	}
+	var tags []model.Tag
+	err := db.DB.Where("article_count > 0").Order("article_count DESC, name").Limit(q.Limit).Find(&tags).Error
+	if err != nil {
+		res.Error(c, http.StatusInternalServerError, err)
+		return
+	}
+	res.Success(c, tags)
+}
+
+// RenameTag 重命名标签
+// @Summary 重命名标签
+// @Description 新名称已被其他标签使用时返回 409，请改用合并
+// @Tags 标签与分类
+// @Accept json
+// @Produce json
+// @Param   Authorization  header  string  true  "Bearer Token"
+// @Param id path int true "标签ID"
+// @Param request body model.RenameTagRequest true "新名称"
+// @Success 200 {object} model.Tag "标签"
+// @Failure 409 {object} middleware.Response "名称已存在"
+// @Router /api/article/tags/{id} [put]
+func RenameTag(c *gin.Context) {
+	var req model.RenameTagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		res.Error(c, http.StatusBadRequest, err)
+		return
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		res.Error(c, http.StatusBadRequest, errors.New("标签名不能为空"))
+		return
+	}
+	id, _ := strconv.Atoi(c.Param("id"))
+	var tag model.Tag
+	if err := db.DB.First(&tag, id).Error; err != nil {
+		res.Error(c, http.StatusNotFound, errors.New("标签不存在"))
+		return
+	}
+	err := db.DB.Model(&tag).Update("name", name).Error
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		res.Error(c, http.StatusConflict, errors.New("标签名已存在，请使用合并"))
+		return
+	}
+	if err != nil {
+		res.Error(c, http.StatusInternalServerError, err)
+		return
+	}
+	res.Success(c, tag)
+}
+
+// MergeTags 合并标签
+// @Summary 合并标签
+// @Description 把 sources 标签下的文章归入 target（不存在时创建），删除 sources 并重新计算 target 的文章数
+// @Tags 标签与分类
+// @Accept json
+// @Produce json
+// @Param   Authorization  header  string  true  "Bearer Token"
+// @Param request body model.MergeTagsRequest true "来源标签与目标标签"
+// @Success 200 {object} model.Tag "合并后的标签"
+// @Failure 404 {object} middleware.Response "来源标签不存在"
+// @Router /api/article/tags/merge [post]
+func MergeTags(c *gin.Context) {
+	var req model.MergeTagsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		res.Error(c, http.StatusBadRequest, err)
+		return
+	}
+	targetName := strings.TrimSpace(req.Target)
+	sources := normalizeTagNames(req.Sources)
+	if targetName == "" || len(sources) == 0 {
+		res.Error(c, http.StatusBadRequest, errors.New("标签名不能为空"))
+		return
+	}
+	var target model.Tag
+	errNoSource := errors.New("来源标签不存在")
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		ids, err := ensureTags(tx, []string{targetName})
+		if err != nil {
+			return err
+		}
+		if err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&target, ids[0]).Error; err != nil {
+			return err
+		}
+		var sourceIDs []uint
+		err = tx.Model(&model.Tag{}).Where("name IN ? AND id <> ?", sources, target.ID).Pluck("id", &sourceIDs).Error
+		if err != nil {
+			return err
+		}
+		if len(sourceIDs) == 0 {
+			return errNoSource
+		}
+		// 同时带有来源与目标标签的文章只保留一条关联
+		err = tx.Exec("INSERT IGNORE INTO article_tags (article_id, tag_id) SELECT article_id, ? FROM article_tags WHERE tag_id IN ?",
+			target.ID, sourceIDs).Error
+		if err != nil {
+			return err
+		}
+		if err = tx.Where("tag_id IN ?", sourceIDs).Delete(&model.ArticleTag{}).Error; err != nil {
+			return err
+		}
+		if err = tx.Delete(&model.Tag{}, sourceIDs).Error; err != nil {
+			return err
+		}
+		var count int64
+		if err = tx.Model(&model.ArticleTag{}).Where("tag_id = ?", target.ID).Count(&count).Error; err != nil {
+			return err
+		}
+		target.ArticleCount = int(count)
+		return tx.Model(&target).UpdateColumn("article_count", count).Error
+	})
+	if errors.Is(err, errNoSource) {
+		res.Error(c, http.StatusNotFound, err)
+		return
+	}
+	if err != nil {
+		res.Error(c, http.StatusInternalServerError, err)
+		return
+	}
+	res.Success(c, target)
+}
+
+// ListCategories 分类树
+// @Summary 分类树
+// @Description 按排序值排列的全部分类，children 为下级分类
+// @Tags 标签与分类
+// @Produce json
+// @Param   Authorization  header  string  true  "Bearer Token"
+// @Success 200 {array} model.CategoryNode "分类树"
+// @Router /api/article/categories [get]
+func ListCategories(c *gin.Context) {
+	categories, err := loadCategories()
+	if err != nil {
+		res.Error(c, http.StatusInternalServerError, err)
+		return
+	}
+	res.Success(c, categoryTree(categories))
+}
+
+// CreateCategory 创建分类
+// @Summary 创建分类
+// @Tags 标签与分类
+// @Accept json
+// @Produce json
+// @Param   Authorization  header  string  true  "Bearer Token"
+// @Param request body model.CategoryRequest true "名称、上级分类与排序值"
+// @Success 200 {object} model.Category "分类"
+// @Failure 409 {object} middleware.Response "同级分类重名"
+// @Router /api/article/categories [post]
+func CreateCategory(c *gin.Context) {
+	var req model.CategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		res.Error(c, http.StatusBadRequest, err)
+		return
+	}
+	category := model.Category{Name: strings.TrimSpace(req.Name), Sort: req.Sort}
+	if req.ParentID != 0 {
+		category.ParentID = &req.ParentID
+	}
+	if err := checkCategory(&category); err != nil {
+		categoryError(c, err)
+		return
+	}
+	if err := db.DB.Create(&category).Error; err != nil {
+		res.Error(c, http.StatusInternalServerError, err)
+		return
+	}
+	res.Success(c, category)
+}
+
+// UpdateCategory 修改分类
+// @Summary 修改分类
+// @Description 重命名、调整排序或移动到其他上级分类下，不能移动到自己的下级分类下
+// @Tags 标签与分类
+// @Accept json
+// @Produce json
+// @Param   Authorization  header  string  true  "Bearer Token"
+// @Param id path int true "分类ID"
+// @Param request body model.CategoryRequest true "名称、上级分类与排序值"
+// @Success 200 {object} model.Category "分类"
+// @Failure 409 {object} middleware.Response "同级分类重名或形成循环"
+// @Router /api/article/categories/{id} [put]
+func UpdateCategory(c *gin.Context) {
+	var req model.CategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		res.Error(c, http.StatusBadRequest, err)
+		return
+	}
+	id, _ := strconv.Atoi(c.Param("id"))
+	var category model.Category
+	if err := db.DB.First(&category, id).Error; err != nil {
+		res.Error(c, http.StatusNotFound, errCategoryNotFound)
+		return
+	}
+	category.Name, category.Sort, category.ParentID = strings.TrimSpace(req.Name), req.Sort, nil
+	if req.ParentID != 0 {
+		category.ParentID = &req.ParentID
+	}
+	if err := checkCategory(&category); err != nil {
+		categoryError(c, err)
+		return
+	}
+	err := db.DB.Model(&category).Select("name", "sort", "parent_id").Updates(&category).Error
+	if err != nil {
+		res.Error(c, http.StatusInternalServerError, err)
+		return
+	}
+	res.Success(c, category)
+}
+
+// DeleteCategory 删除分类
+// @Summary 删除分类
+// @Description 有下级分类时返回 409；文章与该分类的关联一并删除，文章本身不受影响
+// @Tags 标签与分类
+// @Produce json
+// @Param   Authorization  header  string  true  "Bearer Token"
+// @Param id path int true "分类ID"
+// @Success 200 {object} middleware.Response "成功"
+// @Failure 409 {object} middleware.Response "存在下级分类"
+// @Router /api/article/categories/{id} [delete]
+func DeleteCategory(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	var children int64
+	if err := db.DB.Model(&model.Category{}).Where("parent_id = ?", id).Count(&children).Error; err != nil {
+		res.Error(c, http.StatusInternalServerError, err)
+		return
+	}
+	if children > 0 {
+		res.Error(c, http.StatusConflict, errors.New("请先删除或移走下级分类"))
+		return
+	}
+	var deleted int64
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("category_id = ?", id).Delete(&model.ArticleCategory{}).Error; err != nil {
+			return err
+		}
+		result := tx.Delete(&model.Category{}, id)
+		deleted = result.RowsAffected
+		return result.Error
+	})
+	if err != nil {
+		res.Error(c, http.StatusInternalServerError, err)
+		return
+	}
+	if deleted == 0 {
+		res.Error(c, http.StatusNotFound, errCategoryNotFound)
+		return
+	}
+	res.Success(c, "分类已删除")
+}
+
+// checkCategory 校验名称、上级分类是否存在、同级是否重名，以及移动后是否形成循环
+func checkCategory(category *model.Category) error {
+	if category.Name == "" {
+		return errCategoryName
+	}
+	categories, err := loadCategories()
+	if err != nil {
+		return err
+	}
+	if category.ParentID != nil {
+		found := false
+		for _, c := range categories {
+			found = found || c.ID == *category.ParentID
+		}
+		if !found {
+			return errCategoryNotFound
+		}
+		if category.ID != 0 {
+			for _, id := range categoryDescendants(categories, category.ID) {
+				if id == *category.ParentID {
+					return errCategoryCycle
+				}
+			}
+		}
+	}
+	for _, c := range categories {
+		if c.ID != category.ID && derefID(c.ParentID) == derefID(category.ParentID) && strings.EqualFold(c.Name, category.Name) {
+			return errCategoryExists
+		}
+	}
+	return nil
+}
+
+// categoryError 将分类校验错误转换为响应
+func categoryError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, errCategoryNotFound):
+		res.Error(c, http.StatusBadRequest, errors.New("上级分类不存在"))
+	case errors.Is(err, errCategoryName):
+		res.Error(c, http.StatusBadRequest, err)
+	case errors.Is(err, errCategoryExists), errors.Is(err, errCategoryCycle):
+		res.Error(c, http.StatusConflict, err)
+	default:
+		res.Error(c, http.StatusInternalServerError, err)
+	}
+}
@@ -0,0 +1,169 @@
+package api
+
+import (
+	db "TestGin/config"
+	res "TestGin/middleware"
+	"TestGin/model"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 最后使用时间的更新间隔，避免每个请求都写库
+const tokenTouchInterval = time.Minute
+
+// CreateToken 创建个人访问令牌
+// @Summary 创建个人访问令牌
+// @Description 令牌只在创建时返回一次，库中只保存 SHA256。权限范围不能超出当前角色的权限
+// @Tags 个人访问令牌
+// @Accept json
+// @Produce json
+// @Param   Authorization  header  string  true  "Bearer Token"
+// @Param request body model.CreateTokenRequest true "令牌名称、权限范围、有效天数"
+// @Success 200 {object} middleware.Response "令牌（只显示这一次）"
+// @Router /api/user/tokens [post]
+func CreateToken(c *gin.Context) {
+	var req model.CreateTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		res.Error(c, http.StatusBadRequest, err)
+		return
+	}
+	user, err := actingUser(c)
+	if err != nil {
+		res.Error(c, http.StatusUnauthorized, err)
+		return
+	}
+	for _, scope := range req.Scopes {
+		if !res.HasPermission(c.GetString("role"), scope) {
+			res.Error(c, http.StatusForbidden, errors.New("权限范围超出当前角色: "+scope))
+			return
+		}
+	}
+
+	raw, err := generatePAT()
+	if err != nil {
+		res.Error(c, http.StatusInternalServerError, err)
+		return
+	}
+	token := model.PersonalAccessToken{
+		UserID:    user.ID,
+		Name:      req.Name,
+		Prefix:    raw[:len(res.PATPrefix)+6],
+		TokenHash: hashPAT(raw),
+		Scopes:    strings.Join(req.Scopes, ","),
+	}
+	if req.ExpiresInDays > 0 {
+		expiresAt := time.Now().AddDate(0, 0, req.ExpiresInDays)
+		token.ExpiresAt = &expiresAt
+	}
+	if err = db.DB.Create(&token).Error; err != nil {
+		res.Error(c, http.StatusInternalServerError, err)
+		return
+	}
+	res.Success(c, map[string]interface{}{
+		"token": raw,
+		"info":  model.TokenToResponse(token),
+	})
+}
+
+// ListTokens 个人访问令牌列表
+// @Summary 个人访问令牌列表
+// @Tags 个人访问令牌
+// @Produce json
+// @Param   Authorization  header  string  true  "Bearer Token"
+// @Success 200 {array} model.TokenResponse "令牌列表"
+// @Router /api/user/tokens [get]
+func ListTokens(c *gin.Context) {
+	user, err := actingUser(c)
+	if err != nil {
+		res.Error(c, http.StatusUnauthorized, err)
+		return
+	}
+	var tokens []model.PersonalAccessToken
+	if err = db.DB.Where("user_id = ?", user.ID).Order("id desc").Find(&tokens).Error; err != nil {
+		res.Error(c, http.StatusInternalServerError, err)
+		return
+	}
+	list := make([]model.TokenResponse, len(tokens))
+	for i, t := range tokens {
+		list[i] = model.TokenToResponse(t)
+	}
+	res.Success(c, list)
+}
+
+// RevokeToken 撤销个人访问令牌
+// @Summary 撤销个人访问令牌
+// @Tags 个人访问令牌
+// @Produce json
+// @Param   Authorization  header  string  true  "Bearer Token"
+// @Param id path int true "令牌ID"
+// @Success 200 {object} middleware.Response "成功"
+// @Router /api/user/tokens/{id} [delete]
+func RevokeToken(c *gin.Context) {
+	user, err := actingUser(c)
+	if err != nil {
+		res.Error(c, http.StatusUnauthorized, err)
+		return
+	}
+	id, _ := strconv.Atoi(c.Param("id"))
+	result := db.DB.Where("id = ? AND user_id = ?", id, user.ID).Delete(&model.PersonalAccessToken{})
+	if result.Error != nil {
+		res.Error(c, http.StatusInternalServerError, result.Error)
+		return
+	}
+	if result.RowsAffected == 0 {
+		res.Error(c, http.StatusNotFound, errors.New("令牌不存在"))
+		return
+	}
+	res.Success(c, "令牌已撤销")
+}
+
+// AuthenticatePAT 校验个人访问令牌，注入到 JWTAuthMiddleware
+func AuthenticatePAT(raw string) (*res.PATIdentity, error) {
+	var token model.PersonalAccessToken
+	if err := db.DB.Where("token_hash = ?", hashPAT(raw)).First(&token).Error; err != nil {
+		return nil, errors.New("令牌无效")
+	}
+	now := time.Now()
+	if token.ExpiresAt != nil && now.After(*token.ExpiresAt) {
+		return nil, errors.New("令牌已过期")
+	}
+	var user model.User
+	if err := db.DB.First(&user, token.UserID).Error; err != nil {
+		return nil, errors.New("用户不存在")
+	}
+	if err := checkAccountStatus(&user); err != nil {
+		return nil, err
+	}
+	if token.LastUsedAt == nil || now.Sub(*token.LastUsedAt) > tokenTouchInterval {
+		db.DB.Model(&token).UpdateColumn("last_used_at", now)
+	}
+	return &res.PATIdentity{
+		TokenID:  token.ID,
+		UserID:   user.UUID,
+		Username: user.Username,
+		Role:     loginRole(&user),
+		Scopes:   token.ScopeList(),
+	}, nil
+}
+
+// generatePAT 生成令牌：前缀 + 32 字节随机数 (Base32)
+func generatePAT() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return res.PATPrefix + strings.ToLower(base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(buf)), nil
+}
+
+func hashPAT(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
@@ -4,17 +4,21 @@ import (
 	db "TestGin/config"
 	res "TestGin/middleware"
 	"TestGin/model"
+	"TestGin/util"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"github.com/gin-gonic/gin"
 	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
 	"log"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 )
 
-var redisClient = db.GetRedisClient()
-
 // GetUser 获取用户信息
 // @Summary 获取用户信息
 // @Tags 用户
@@ -43,31 +47,90 @@ func GetUser(c *gin.Context) {
 // Register  添加用户
 // @Summary 添加用户
 // @Tags 用户
+// @Description 注册新用户。register.mode 为 invite 时必须提供 invite_code，为 closed 时暂停注册；账号、用户名、邮箱已被占用时返回 409
+// @Accept json
 // @Produce json
-// @Param   Authorization  header  string  true  "Bearer Token"
-// @Param user body model.User true "用户信息"
+// @Param user body model.RegisterRequest true "用户信息"
 // @Success 200 {object} string "成功"
+// @Failure 403 {object} middleware.Response "暂停注册或需要邀请码"
+// @Failure 409 {object} middleware.Response "账号、用户名或邮箱已被占用"
 // @Router /api/user/add [POST]
 func Register(c *gin.Context) {
-	var user model.User
-	if err := c.ShouldBindJSON(&user); err != nil {
+	var req model.RegisterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(400, gin.H{
 			"message": "参数绑定失败",
 			"error":   err.Error(),
 		})
 		return
 	}
-	if err := db.DB.Where("email = ?", user.Email).Table("users").Error; err != nil {
-		res.Error(c, http.StatusBadRequest, errors.New("邮箱已被占用"))
+	mode := registerMode()
+	if mode == RegisterModeClosed {
+		res.Error(c, http.StatusForbidden, errors.New("暂停注册"))
+		return
+	}
+	if mode == RegisterModeInvite && strings.TrimSpace(req.InviteCode) == "" {
+		res.Error(c, http.StatusForbidden, errors.New("注册需要邀请码"))
+		return
+	}
+	// 角色、状态等字段不允许由请求体设置
+	user := model.User{
+		Account:  strings.TrimSpace(req.Account),
+		Username: strings.TrimSpace(req.Username),
+		Password: req.Password,
+		Email:    req.Email,
+		Phone:    req.Phone,
+		Role:     "user",
+	}
+	if err := normalizeContact(&user); err != nil {
+		res.Error(c, http.StatusBadRequest, err)
 		return
 	}
-	if err := db.DB.Create(&user).Error; err != nil {
+	if err := db.GetPasswordPolicy().Validate(user.Password, user.Username, user.Account, user.Email); err != nil {
+		res.Error(c, http.StatusBadRequest, err)
+		return
+	}
+	if err := checkUnique(&user, 0); err != nil {
+		res.Error(c, http.StatusConflict, err)
+		return
+	}
+	// 未验证邮箱前使用受限状态
+	user.Status = "active"
+	user.EmailVerified = false
+	if status := db.Conf.Mail.UnverifiedStatus; status != "" {
+		user.Status = status
+	}
+	// 开放注册时也可以使用邀请码，用于记录邀请关系
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		if req.InviteCode != "" {
+			invite, err := redeemInvite(tx, req.InviteCode)
+			if err != nil {
+				return err
+			}
+			user.InvitedBy, user.InviteCodeID = &invite.CreatorID, &invite.ID
+		}
+		return tx.Create(&user).Error
+	})
+	switch {
+	case errors.Is(err, errInviteInvalid):
+		res.Error(c, http.StatusForbidden, err)
+		return
+	case errors.Is(err, gorm.ErrDuplicatedKey):
+		// 并发注册时由唯一索引兜底
+		res.Error(c, http.StatusConflict, errors.New("账号、用户名或邮箱已被占用"))
+		return
+	case err != nil:
 		c.JSON(500, gin.H{
 			"message": "用户添加失败",
 			"error":   err.Error(),
 		})
 		return
 	}
+	if user.Email != "" {
+		if err := sendActionMail(&user, res.ActionEmailVerify); err != nil {
+			log.Printf("发送验证邮件失败: %v", err)
+		}
+	}
 	c.JSON(200, gin.H{
 		"message": "用户添加成功",
 		"data":    "",
@@ -75,6 +138,31 @@ func Register(c *gin.Context) {
 	return
 }
 
+// checkUnique 检查账号、用户名、邮箱是否已被其他用户占用（含已软删除的用户，与唯一索引一致），空值不检查
+func checkUnique(user *model.User, excludeID uint) error {
+	fields := []struct{ column, value, name string }{
+		{"account", user.Account, "账号"},
+		{"username", user.Username, "用户名"},
+		{"email", user.Email, "邮箱"},
+	}
+	for _, f := range fields {
+		if f.value == "" {
+			continue
+		}
+		var count int64
+		err := db.DB.Unscoped().Model(&model.User{}).
+			Where(f.column+" = ? AND id <> ?", f.value, excludeID).
+			Count(&count).Error
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			return fmt.Errorf("%s已被占用", f.name)
+		}
+	}
+	return nil
+}
+
 // ListUsers 用户列表
 // @Summary 获取用户列表
 // @Tags 用户
@@ -103,149 +191,458 @@ func ListUsers(c *gin.Context) {
 // UpdateUser 更新用户数据
 // @Summary 更新用户数据
 // @Tags 用户
-// @Description 更新用户数据
+// @Description 更新当前登录用户的资料（account、username、email、phone），只修改请求体中出现的字段
+// @Accept json
 // @Produce json
-// @Param user body model.User true "用户信息"
+// @Param   Authorization  header  string  true  "Bearer Token"
+// @Param user body model.UpdateUserRequest true "用户信息"
 // @Success 200 {object} middleware.Response "成功"
+// @Failure 409 {object} middleware.Response "账号、用户名或邮箱已被占用"
 // @Router /api/user/update/user [POST]
 func UpdateUser(c *gin.Context) {
-	var user model.User
-	//开启事务
-	tx := db.DB.Begin()
-	if err := c.ShouldBindJSON(&user); err != nil {
+	var req model.UpdateUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
 		res.Error(c, http.StatusBadRequest, err)
 		return
 	}
-	err := tx.Where("id = ?", user.UUID).Updates(&user).Error
+	// 只能修改自己的资料，身份取自令牌而不是请求体
+	current, err := actingUser(c)
+	if err != nil {
+		res.Error(c, http.StatusUnauthorized, err)
+		return
+	}
+	// candidate 只填写要修改的字段，用于唯一性检查
+	var candidate model.User
+	updates := map[string]interface{}{}
+	if req.Account != nil {
+		candidate.Account = strings.TrimSpace(*req.Account)
+		updates["account"] = candidate.Account
+	}
+	if req.Username != nil {
+		candidate.Username = strings.TrimSpace(*req.Username)
+		updates["username"] = candidate.Username
+	}
+	// 修改邮箱后需要重新验证
+	emailChanged := false
+	if req.Email != nil {
+		candidate.Email = model.NormalizeEmail(*req.Email)
+		if candidate.Email != current.Email {
+			emailChanged = true
+			updates["email"] = model.NullIfEmpty(candidate.Email)
+			updates["email_verified"] = false
+		}
+	}
+	if req.Phone != nil {
+		phone := strings.TrimSpace(*req.Phone)
+		if phone != "" {
+			if phone, err = util.NormalizePhone(phone, db.Conf.Login.DefaultCountryCode); err != nil {
+				res.Error(c, http.StatusBadRequest, err)
+				return
+			}
+		}
+		updates["phone"] = phone
+	}
+	if (req.Account != nil && candidate.Account == "") || (req.Username != nil && candidate.Username == "") {
+		res.Error(c, http.StatusBadRequest, errors.New("账号与用户名不能为空"))
+		return
+	}
+	if len(updates) == 0 {
+		res.Success(c, "资料没有变化")
+		return
+	}
+	if err = checkUnique(&candidate, current.ID); err != nil {
+		res.Error(c, http.StatusConflict, err)
+		return
+	}
+	uuid := current.UUID
+	err = db.DB.Model(&model.User{}).Where("uuid = ?", uuid).Updates(updates).Error
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		res.Error(c, http.StatusConflict, errors.New("账号、用户名或邮箱已被占用"))
+		return
+	}
 	if err != nil {
-		//回滚事务
-		tx.Rollback()
 		res.Error(c, 500, err)
 		return
 	}
-	tx.Commit()
+	if emailChanged {
+		// 发往旧邮箱的验证链接作废
+		if err = res.InvalidateEmailVerify(uuid); err != nil {
+			log.Printf("作废邮箱验证令牌失败: %v", err)
+		}
+	}
 	//同步更新到Redis
-	ctx := context.Background()
-	err = redisClient.Set(ctx, fmt.Sprintf("user:%s", user.UUID), user, 0).Err()
-	if err != nil {
+	var updated model.User
+	if err = db.DB.Where("uuid = ?", uuid).First(&updated).Error; err != nil {
 		res.Error(c, 500, err)
 		return
 	}
+	if emailChanged && updated.Email != "" {
+		if err = sendActionMail(&updated, res.ActionEmailVerify); err != nil {
+			log.Printf("发送验证邮件失败: %v", err)
+		}
+	}
+	if rdb := db.GetRedisClient(); rdb != nil {
+		data, _ := json.Marshal(model.UserToResponse(updated))
+		if err = rdb.Set(context.Background(), fmt.Sprintf("user:%s", uuid), data, 0).Err(); err != nil {
+			res.Error(c, 500, err)
+			return
+		}
+	}
 
-	log.Printf("%+v\n", user)
 	res.Success(c, "更新用户成功")
 }
 
 // UpdatePassword 更新用户密码
 // @Summary 更新用户密码
-// @Description 更新用户密码
+// @Description 校验旧密码后设置新密码，新密码需满足密码策略；成功后除当前会话外的所有会话失效
+// @Accept json
 // @Produce json
 // @Tags 用户
-// @Param user body model.User true "用户信息"
+// @Param   Authorization  header  string  true  "Bearer Token"
+// @Param request body model.UpdatePasswordRequest true "旧密码与新密码"
 // @Success 200 {object} middleware.Response "成功"
 // @Router /api/user/update/password [POST]
 func UpdatePassword(c *gin.Context) {
-	//加强修改密码的流程
-	var user model.User
-	if err := c.ShouldBindJSON(&user); err != nil {
+	var req model.UpdatePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
 		res.Error(c, http.StatusBadRequest, err)
 		return
 	}
-	//if user.Password != user.NewPassword {
-	//	res.Error(c, http.StatusBadRequest, errors.New("新密码和旧密码不一致"))
-	//	return
-	//}
-	if err := db.DB.Where("id = ?", user.UUID).Updates(&user).Error; err != nil {
-		res.Error(c, 500, err)
+	user, err := actingUser(c)
+	if err != nil {
+		res.Error(c, http.StatusUnauthorized, err)
+		return
+	}
+
+	// 旧密码校验与登录共用失败计数，防止持有令牌者暴力猜测
+	guard, ip := "pwd:"+user.UUID, c.ClientIP()
+	if err = res.CheckLoginAllowed(guard, ip); err != nil {
+		loginBlocked(c, err)
+		return
+	}
+	if err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.OldPassword)); err != nil {
+		res.RecordLoginFailure(guard, ip)
+		res.Error(c, http.StatusBadRequest, errors.New("旧密码错误"))
 		return
 	}
+	res.ResetLoginFailures(guard, ip)
 
+	if req.NewPassword == req.OldPassword {
+		res.Error(c, http.StatusBadRequest, errors.New("新密码不能与旧密码相同"))
+		return
+	}
+	if err = db.GetPasswordPolicy().Validate(req.NewPassword, user.Username, user.Account, user.Email); err != nil {
+		res.Error(c, http.StatusBadRequest, err)
+		return
+	}
+	hashed, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		res.Error(c, http.StatusInternalServerError, err)
+		return
+	}
+	if err = db.DB.Model(user).Update("password", string(hashed)).Error; err != nil {
+		res.Error(c, http.StatusInternalServerError, err)
+		return
+	}
+	// 其他设备上的会话全部失效，当前会话保留
+	if err = res.RevokeOtherSessions(user.UUID, c.GetString("sessionID")); err != nil {
+		log.Printf("撤销用户 %s 的其他会话失败: %v", user.UUID, err)
+	}
+	res.RecordAuthEventFromContext(c, res.AuthEventPasswordChange, user.UUID, "其他会话已撤销")
 	res.Success(c, "更新密码成功")
 }
 
+// normalizeContact 规范化邮箱与手机号，保证与登录时的查找方式一致
+func normalizeContact(user *model.User) error {
+	user.Email = model.NormalizeEmail(user.Email)
+	if user.Phone != "" {
+		phone, err := util.NormalizePhone(user.Phone, db.Conf.Login.DefaultCountryCode)
+		if err != nil {
+			return err
+		}
+		user.Phone = phone
+	}
+	return nil
+}
+
 // Login 登录
 // @Summary 登录
-// @Description 登录
+// @Description 使用 account、email、phone 之一加密码登录。访问令牌在 Authorization 响应头中，刷新令牌写入 refresh_token Cookie
+// @Accept json
+// @Produce json
+// @Tags 登录
+// @Param request body model.LoginRequest true "登录信息"
+// @Success 200 {object} middleware.Response "成功；开启两步验证时返回 mfa_token，需调用 /api/user/login/mfa"
+// @Failure 423 {object} middleware.Response "多次失败被临时锁定 (code=42301)"
+// @Failure 429 {object} middleware.Response "失败后退避中 (code=42901)"
+// @Router /api/user/login [POST]
+func Login(c *gin.Context) {
+	var req model.LoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		res.Error(c, http.StatusBadRequest, err)
+		return
+	}
+	column, value, err := req.Identifier()
+	if err != nil {
+		res.Error(c, http.StatusBadRequest, err)
+		return
+	}
+	passwordLogin(c, column, value, req.Password)
+}
+
+// LoginLegacy 登录（已废弃）
+// @Summary 登录（已废弃）
+// @Description 密码出现在查询串中会被记录到访问日志，请改用 POST /api/user/login。仅在配置 login.allowlegacyget 开启时可用
 // @Produce json
 // @Tags 登录
-// @Param Email query string true "用户邮箱"
-// @Param Account query string true "账号"
-// @Param Phone query string true "手机号"
+// @Param Email query string false "用户邮箱"
+// @Param Account query string false "账号"
+// @Param Phone query string false "手机号"
 // @Param Password query string true "用户密码"
 // @Success 200 {object} middleware.Response "成功"
+// @Deprecated
 // @Router /api/user/login [GET]
-func Login(c *gin.Context) {
+func LoginLegacy(c *gin.Context) {
+	c.Header("Deprecation", "true")
+	c.Header("Link", `</api/user/login>; rel="successor-version"`)
+	req := model.LoginRequest{
+		Account:  c.Query("Account"),
+		Email:    c.Query("Email"),
+		Phone:    c.Query("Phone"),
+		Password: c.Query("Password"),
+	}
+	column, value, err := req.Identifier()
+	if err != nil || req.Password == "" {
+		res.Error(c, 400, errors.New("参数错误"))
+		return
+	}
+	passwordLogin(c, column, value, req.Password)
+}
 
-	//根据用户名以及密码查询用户
+// passwordLogin 按规范化后的标识查找用户、校验密码并签发令牌
+func passwordLogin(c *gin.Context, column, value, password string) {
 	var user model.User
-	var err error
-	email := c.Query("Email")
-	//account := c.Query("Account")
-	phone := c.Query("Phone")
-	password := c.Query("Password")
+	// 规范化登录标识：邮箱转小写，手机号转为 E.164
+	switch column {
+	case "email":
+		value = model.NormalizeEmail(value)
+	case "phone":
+		phone, err := util.NormalizePhone(value, db.Conf.Login.DefaultCountryCode)
+		if err != nil {
+			res.Error(c, http.StatusBadRequest, err)
+			return
+		}
+		value = phone
+	}
 
-	if password == "" || (email == "" && phone == "") {
-		res.Error(c, 400, errors.New("参数错误"))
+	// 检查 IP 与登录标识是否因多次失败被限制
+	ip := c.ClientIP()
+	if err := res.CheckLoginAllowed(res.IdentifierLoginKey(value), ip); err != nil {
+		loginBlocked(c, err)
 		return
 	}
 
-	// 查找用户（只按 email 或 phone）
-	query := db.DB.Table("users")
-	if phone != "" {
-		query = query.Where("phone = ?", phone)
-	} else {
-		query = query.Where("email = ?", email)
-	}
-	if err := query.First(&user).Error; err != nil {
+	if err := db.DB.Where(column+" = ?", value).First(&user).Error; err != nil {
+		res.RecordLoginFailure(res.IdentifierLoginKey(value), ip)
+		res.RecordAuthEventFromContext(c, res.AuthEventLoginFailure, "", "用户不存在: "+value)
 		res.Error(c, 400, errors.New("用户不存在"))
 		return
 	}
 
-	log.Printf("user: %v", user)
+	// 查到用户后按 UUID 计数，轮换账号名、邮箱、手机号无法绕过账号锁定
+	key := res.UserLoginKey(user.UUID)
+	if err := res.CheckLoginAllowed(key, ip); err != nil {
+		loginBlocked(c, err)
+		return
+	}
+
 	// 验证密码（bcrypt）
 	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
+		res.RecordLoginFailure(key, ip)
+		res.RecordAuthEventFromContext(c, res.AuthEventLoginFailure, user.UUID, "密码错误")
 		res.Error(c, 400, errors.New("密码错误"))
 		return
 	}
-	token, errs := res.Login(user.UUID, user.Username)
-	if errs != nil {
+	res.ResetLoginFailures(key, ip)
+	completeLogin(c, &user)
+}
+
+// completeLogin 第一因素验证通过后完成登录：开启两步验证的用户先返回临时令牌，校验动态码后再签发令牌对；
+// 开启 loginalert.stepup 时，其他用户的异常登录需要先校验邮件验证码
+func completeLogin(c *gin.Context, user *model.User) {
+	if err := checkAccountStatus(user); err != nil {
+		res.RecordAuthEventFromContext(c, res.AuthEventLoginFailure, user.UUID, err.Error())
+		res.ErrorWithCode(c, http.StatusForbidden, res.CodeAccountDisabled, err)
+		return
+	}
+	if user.TOTPEnabled {
+		mfaToken, err := res.CreateMFAToken(user.UUID)
+		if err != nil {
+			res.Error(c, http.StatusInternalServerError, err)
+			return
+		}
+		res.Success(c, map[string]interface{}{
+			"UUID":         user.UUID,
+			"mfa_required": true,
+			"mfa_token":    mfaToken,
+		})
+		return
+	}
+	// 未开启两步验证的用户，新设备或新网络登录需要邮件验证码
+	device := loginDevice(c)
+	reasons := assessLogin(user, device)
+	if db.Conf.LoginAlert.StepUp && len(reasons) > 0 {
+		beginStepUp(c, user, reasons)
+		return
+	}
+	issueLogin(c, user, device, reasons)
+}
+
+// issueLogin 创建会话并返回令牌：访问令牌放在响应头，刷新令牌写入 Cookie。
+// reasons 为 assessLogin 的结果，非空时通知用户有新设备或新网络登录
+func issueLogin(c *gin.Context, user *model.User, device res.DeviceInfo, reasons []string) {
+	token, err := res.Login(user.UUID, user.Username, loginRole(user), device)
+	if err != nil {
 		res.Error(c, 400, err)
 		return
 	}
+	if len(reasons) > 0 {
+		notifyUnusualLogin(user, device, reasons)
+	}
 	//把token添加到响应头中
 	c.Header("Authorization", "Bearer "+token.AccessToken)
-	c.SetCookie("refresh_token", token.RefreshToken, 3600*24*7, "/", "", true, true)
+	csrf, err := res.SetRefreshCookie(c, token.RefreshToken)
+	if err != nil {
+		res.Error(c, http.StatusInternalServerError, err)
+		return
+	}
 	res.Success(c, map[string]interface{}{
-		"UUID":    user.UUID,
-		"Message": "登录成功",
+		"UUID":       user.UUID,
+		"Message":    "登录成功",
+		"csrf_token": csrf,
 	})
+}
+
+// checkAccountStatus 只允许 active 与未验证邮箱状态的用户登录；临时禁用到期后自动恢复为 active
+func checkAccountStatus(user *model.User) error {
+	now := time.Now()
+	if user.Status == "disabled" && !user.IsDisabled(now) {
+		err := db.DB.Model(user).Updates(map[string]interface{}{
+			"status":          "active",
+			"disabled_reason": "",
+			"disabled_until":  nil,
+		}).Error
+		if err != nil {
+			return err
+		}
+		res.EnableUser(user.UUID)
+	}
+	switch {
+	case user.Status == "active":
+		return nil
+	case user.Status != "" && user.Status == db.Conf.Mail.UnverifiedStatus:
+		return nil
+	case user.IsDisabled(now):
+		msg := "账号已被禁用"
+		if user.DisabledReason != "" {
+			msg += "：" + user.DisabledReason
+		}
+		if user.DisabledUntil != nil {
+			msg += "，解禁时间 " + util.FormatTime(*user.DisabledUntil)
+		}
+		return errors.New(msg)
+	default:
+		return fmt.Errorf("账号状态异常: %s", user.Status)
+	}
+}
 
+// loginRole 令牌中使用的角色：处于未验证状态的用户使用与状态同名的受限角色，权限在 rbac.roles 中配置
+func loginRole(user *model.User) string {
+	if status := db.Conf.Mail.UnverifiedStatus; status != "" && user.Status == status {
+		return status
+	}
+	return user.Role
+}
+
+// loginBlocked 返回登录限制错误：锁定返回 423，退避返回 429，并带上 Retry-After
+func loginBlocked(c *gin.Context, err error) {
+	var blocked *res.LoginBlockedError
+	if !errors.As(err, &blocked) {
+		res.Error(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.Header("Retry-After", strconv.Itoa(int(blocked.RetryAfter.Seconds())+1))
+	if blocked.Locked {
+		res.ErrorWithCode(c, http.StatusLocked, res.CodeAccountLocked, err)
+		return
+	}
+	res.ErrorWithCode(c, http.StatusTooManyRequests, res.CodeLoginThrottled, err)
 }
 
 // RefreshToken 刷新token
 // @Summary 刷新token
-// @Description 刷新token
+// @Description 使用 refresh_token Cookie 刷新令牌：新的访问令牌在 Authorization 响应头中，刷新令牌与 CSRF 令牌同时轮换，新的 CSRF 令牌在响应体 csrf_token 中。
+// @Description 需要在 X-CSRF-Token 头中带上 csrf_token Cookie（Path=/，页面脚本可读）的值，跨站来源返回 403
 // @Tags 登录
-// @Accept json
 // @Produce json
-// @Param refresh_token formData string true "刷新token"
+// @Param X-CSRF-Token header string true "csrf_token Cookie 的值"
+// @Success 200 {object} middleware.Response "成功"
+// @Failure 403 {object} middleware.Response "CSRF 校验失败"
 // @Router /api/user/refresh [post]
 func RefreshToken(c *gin.Context) {
-	rtk := c.PostForm("refresh_token")
-	if rtk == "" {
-		res.Error(c, 400, errors.New("参数错误"))
+	rtk, err := c.Cookie(res.RefreshCookieName)
+	if err != nil || rtk == "" {
+		res.Error(c, http.StatusUnauthorized, errors.New("缺少刷新令牌"))
 		return
 	}
 	token, err := res.RefreshAccessToken(rtk)
 	if err != nil {
-		res.Error(c, 400, err)
+		res.ClearRefreshCookie(c)
+		if claims, perr := res.ParseToken(rtk); perr == nil {
+			recordSessionEvent(c, res.AuthEventRefresh, claims, "刷新失败: "+err.Error())
+		}
+		res.Error(c, http.StatusUnauthorized, err)
 		return
 	}
 	c.Header("Authorization", "Bearer "+token.AccessToken)
-	c.SetCookie("refresh_token", token.RefreshToken, 3600*24*7, "/", "", true, true)
-	//if err != nil {
-	//	res.Error(c, 400, err)
-	//	return
-	//}
-	res.Success(c, "")
+	csrf, err := res.SetRefreshCookie(c, token.RefreshToken)
+	if err != nil {
+		res.Error(c, http.StatusInternalServerError, err)
+		return
+	}
+	if claims, err := res.ParseToken(token.AccessToken); err == nil {
+		recordSessionEvent(c, res.AuthEventRefresh, claims, "")
+	}
+	res.Success(c, map[string]string{"csrf_token": csrf})
+}
+
+// Logout 注销
+// @Summary 注销
+// @Description 注销当前会话：访问令牌加入黑名单直至过期，刷新令牌失效
+// @Tags 登录
+// @Produce json
+// @Param   Authorization  header  string  true  "Bearer Token"
+// @Success 200 {object} middleware.Response "成功"
+// @Router /api/user/logout [post]
+func Logout(c *gin.Context) {
+	claims := c.MustGet("claims").(*res.Claims)
+	if err := res.Logout(claims); err != nil {
+		res.Error(c, 500, err)
+		return
+	}
+	res.ClearRefreshCookie(c)
+	res.RecordAuthEventFromContext(c, res.AuthEventLogout, claims.UserID, "")
+	res.Success(c, "注销成功")
+}
+
+// recordSessionEvent 记录未经过 JWTAuthMiddleware 的请求（如刷新）的审计事件，用户与会话取自令牌
+func recordSessionEvent(c *gin.Context, eventType string, claims *res.Claims, reason string) {
+	res.RecordAuthEvent(&res.AuthEvent{
+		Type:      eventType,
+		UserID:    claims.UserID,
+		SessionID: claims.SessionID,
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+		Reason:    reason,
+	})
 }
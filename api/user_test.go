@@ -0,0 +1,236 @@
+package api
+
+import (
+	db "TestGin/config"
+	res "TestGin/middleware"
+	"TestGin/model"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+const testPassword = "Passw0rd!2025"
+
+// setupAPI 使用 SQLite 临时库与内存存储注册全部路由，不依赖 MySQL 与 Redis
+func setupAPI(t *testing.T) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	conn, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{
+		TranslateError: true,
+		Logger:         logger.Discard,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	model.AutoMigrate(conn)
+	if err = model.AutoMigrateMFA(conn); err != nil {
+		t.Fatal(err)
+	}
+	if err = model.AutoMigrateAuthEvent(conn); err != nil {
+		t.Fatal(err)
+	}
+	db.DB = conn
+	db.Conf = &db.Config{}
+	t.Cleanup(func() { db.DB = nil })
+
+	res.InitJWTMiddleware(res.NewMemorySessionStore())
+	t.Setenv("TEST_JWT_SECRET", "test-secret-key-at-least-32-characters")
+	err = res.InitJWTKeys(db.JWTConfig{
+		Issuer:    "testgin",
+		ActiveKID: "test",
+		Keys:      []db.JWTKeyConfig{{KID: "test", Alg: "HS256", SecretEnv: "TEST_JWT_SECRET"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.InitRBAC(db.RBACConfig{Roles: map[string][]string{"user": {"user:read", "user:update"}}})
+	// 退避时间缩短到 1ms，测试中等待几毫秒即可继续尝试
+	res.InitLoginGuard(db.LoginConfig{
+		MaxAccountFailures: 3,
+		BackoffBase:        time.Millisecond,
+		BackoffMax:         time.Millisecond,
+	})
+	res.InitCookie(db.CookieConfig{Insecure: true})
+
+	r := gin.New()
+	RegisterRoutes(r)
+	return r
+}
+
+// createTestUser 创建账号为 account 的用户，密码为 testPassword
+func createTestUser(t *testing.T, account string, totp bool) *model.User {
+	t.Helper()
+	user := &model.User{
+		Account:     account,
+		Username:    account,
+		Password:    testPassword,
+		Role:        "user",
+		Status:      "active",
+		TOTPEnabled: totp,
+	}
+	if err := db.DB.Create(user).Error; err != nil {
+		t.Fatal(err)
+	}
+	return user
+}
+
+type testResponse struct {
+	Code int                    `json:"code"`
+	Data map[string]interface{} `json:"data"`
+}
+
+func decodeResponse(t *testing.T, w *httptest.ResponseRecorder) testResponse {
+	t.Helper()
+	var body testResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("响应不是 JSON: %s", w.Body.String())
+	}
+	return body
+}
+
+func postLogin(r *gin.Engine, account, password string) *httptest.ResponseRecorder {
+	body, _ := json.Marshal(map[string]string{"account": account, "password": password})
+	req := httptest.NewRequest(http.MethodPost, "/api/user/login", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+// liveCookies 响应中下发的 Cookie，去掉删除旧 Cookie 的指令（MaxAge<0）
+func liveCookies(w *httptest.ResponseRecorder) map[string]*http.Cookie {
+	cookies := map[string]*http.Cookie{}
+	for _, ck := range w.Result().Cookies() {
+		if ck.MaxAge >= 0 {
+			cookies[ck.Name] = ck
+		}
+	}
+	return cookies
+}
+
+func TestLoginSuccess(t *testing.T) {
+	r := setupAPI(t)
+	user := createTestUser(t, "alice", false)
+
+	w := postLogin(r, "alice", testPassword)
+	if w.Code != http.StatusOK {
+		t.Fatalf("登录应成功，实际 %d: %s", w.Code, w.Body.String())
+	}
+	body := decodeResponse(t, w)
+	if body.Data["UUID"] != user.UUID {
+		t.Fatalf("应返回用户 UUID，实际 %v", body.Data["UUID"])
+	}
+	claims, err := res.ParseToken(w.Header().Get("Authorization")[len("Bearer "):])
+	if err != nil || claims.UserID != user.UUID {
+		t.Fatalf("Authorization 头应带有访问令牌: %v", err)
+	}
+	cookies := liveCookies(w)
+	if ck := cookies[res.RefreshCookieName]; ck == nil || !ck.HttpOnly {
+		t.Fatal("应下发 HttpOnly 的刷新令牌 Cookie")
+	}
+	if ck := cookies[res.CSRFCookieName]; ck == nil || ck.Value != body.Data["csrf_token"] {
+		t.Fatal("响应体中的 csrf_token 应与 Cookie 一致")
+	}
+}
+
+func TestLoginLockout(t *testing.T) {
+	r := setupAPI(t)
+	createTestUser(t, "alice", false)
+
+	for i := 0; i < 3; i++ {
+		if w := postLogin(r, "alice", "wrong-password"); w.Code != http.StatusBadRequest {
+			t.Fatalf("第 %d 次密码错误应返回 400，实际 %d", i+1, w.Code)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	// 锁定后正确的密码也不能登录
+	w := postLogin(r, "alice", testPassword)
+	if w.Code != http.StatusLocked || decodeResponse(t, w).Code != res.CodeAccountLocked {
+		t.Fatalf("连续失败后应锁定，实际 %d: %s", w.Code, w.Body.String())
+	}
+	if w.Header().Get("Retry-After") == "" {
+		t.Fatal("锁定响应应带 Retry-After")
+	}
+	// 换用其他标识登录同一用户同样被锁定
+	if err := db.DB.Model(&model.User{}).Where("account = ?", "alice").Update("email", "alice@example.com").Error; err != nil {
+		t.Fatal(err)
+	}
+	body, _ := json.Marshal(map[string]string{"email": "alice@example.com", "password": testPassword})
+	req := httptest.NewRequest(http.MethodPost, "/api/user/login", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusLocked {
+		t.Fatalf("换用邮箱登录也应被锁定，实际 %d", w.Code)
+	}
+}
+
+func TestLoginMFARequired(t *testing.T) {
+	r := setupAPI(t)
+	createTestUser(t, "bob", true)
+
+	w := postLogin(r, "bob", testPassword)
+	if w.Code != http.StatusOK {
+		t.Fatalf("密码正确应返回 200，实际 %d: %s", w.Code, w.Body.String())
+	}
+	body := decodeResponse(t, w)
+	if body.Data["mfa_required"] != true || body.Data["mfa_token"] == "" {
+		t.Fatalf("开启两步验证时应返回 mfa_token: %v", body.Data)
+	}
+	if w.Header().Get("Authorization") != "" || liveCookies(w)[res.RefreshCookieName] != nil {
+		t.Fatal("校验动态码之前不应签发令牌")
+	}
+	if _, err := res.ParseMFAToken(body.Data["mfa_token"].(string)); err != nil {
+		t.Fatalf("mfa_token 应可解析: %v", err)
+	}
+}
+
+func TestRefreshRequiresCSRFToken(t *testing.T) {
+	r := setupAPI(t)
+	createTestUser(t, "carol", false)
+	login := postLogin(r, "carol", testPassword)
+	if login.Code != http.StatusOK {
+		t.Fatalf("登录失败: %s", login.Body.String())
+	}
+	cookies := liveCookies(login)
+
+	refresh := func(csrf string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/user/refresh", nil)
+		for _, ck := range cookies {
+			req.AddCookie(ck)
+		}
+		if csrf != "" {
+			req.Header.Set(res.CSRFHeaderName, csrf)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := refresh(""); w.Code != http.StatusForbidden {
+		t.Fatalf("缺少 CSRF 头应返回 403，实际 %d", w.Code)
+	}
+	if w := refresh("forged"); w.Code != http.StatusForbidden {
+		t.Fatalf("CSRF 令牌不一致应返回 403，实际 %d", w.Code)
+	}
+	w := refresh(cookies[res.CSRFCookieName].Value)
+	if w.Code != http.StatusOK {
+		t.Fatalf("带正确 CSRF 令牌应刷新成功，实际 %d: %s", w.Code, w.Body.String())
+	}
+	if w.Header().Get("Authorization") == "" {
+		t.Fatal("刷新后应返回新的访问令牌")
+	}
+	rotated := liveCookies(w)
+	if csrf := decodeResponse(t, w).Data["csrf_token"]; csrf == cookies[res.CSRFCookieName].Value || csrf != rotated[res.CSRFCookieName].Value {
+		t.Fatal("刷新后 CSRF 令牌应轮换，并在响应体中返回")
+	}
+}
@@ -0,0 +1,37 @@
+package config
+
+import (
+	"TestGin/util"
+	"log"
+)
+
+var (
+	geoIP    *util.GeoIP
+	notifier util.Notifier = util.LogNotifier{}
+)
+
+// InitLoginAlert 加载本地 GeoIP 数据库并创建异常登录提醒发送器，需在 InitMailer 之后调用
+func InitLoginAlert() {
+	a := Conf.LoginAlert
+	if a.GeoIPFile != "" {
+		db, err := util.LoadGeoIP(a.GeoIPFile)
+		if err != nil {
+			log.Printf("加载 GeoIP 数据库失败: %v", err)
+		} else {
+			geoIP = db
+		}
+	}
+	if a.Notifier == "mail" {
+		notifier = &util.MailNotifier{Mailer: GetMailer()}
+	}
+}
+
+// GetGeoIP 获取 GeoIP 数据库，未配置时为 nil（查询结果为空）
+func GetGeoIP() *util.GeoIP {
+	return geoIP
+}
+
+// GetNotifier 获取异常登录提醒发送器
+func GetNotifier() util.Notifier {
+	return notifier
+}
@@ -0,0 +1,36 @@
+package config
+
+import (
+	"TestGin/model"
+	"log"
+	"time"
+)
+
+// StartAuditPurge 定期清理超过保留期的认证审计日志，保留期为 0 时不清理
+func StartAuditPurge() {
+	retention, interval := Conf.Audit.Retention, Conf.Audit.PurgeInterval
+	if retention <= 0 {
+		return
+	}
+	if interval <= 0 {
+		interval = 24 * time.Hour
+	}
+	go func() {
+		for {
+			PurgeAuthEvents(retention)
+			time.Sleep(interval)
+		}
+	}()
+}
+
+// PurgeAuthEvents 删除早于保留期的审计日志
+func PurgeAuthEvents(retention time.Duration) {
+	result := DB.Where("created_at < ?", time.Now().Add(-retention)).Delete(&model.AuthEvent{})
+	if result.Error != nil {
+		log.Printf("清理审计日志失败: %v", result.Error)
+		return
+	}
+	if result.RowsAffected > 0 {
+		log.Printf("已清理 %d 条过期审计日志", result.RowsAffected)
+	}
+}
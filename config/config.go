@@ -3,12 +3,26 @@ package config
 import (
 	"fmt"
 	"github.com/spf13/viper"
+	"time"
 )
 
 type Config struct {
-	Server ServerConfig
-	MySQL  MySQLConfig
-	Redis  RedisConfig
+	Server     ServerConfig
+	MySQL      MySQLConfig
+	Redis      RedisConfig
+	Session    SessionConfig
+	Cookie     CookieConfig
+	JWT        JWTConfig
+	RBAC       RBACConfig
+	Login      LoginConfig
+	Mail       MailConfig
+	Password   PasswordConfig
+	OIDC       OIDCConfig
+	Audit      AuditConfig
+	LoginAlert LoginAlertConfig
+	Register   RegisterConfig
+	Search     SearchConfig
+	Article    ArticleConfig
 }
 
 type ServerConfig struct {
@@ -25,6 +39,20 @@ type MySQLConfig struct {
 	MaxOpenConns int
 }
 
+// SessionConfig 认证状态存储配置
+type SessionConfig struct {
+	Store string // redis（默认）/ memory，memory 只适用于单机开发与测试
+}
+
+// CookieConfig 刷新令牌 Cookie 配置
+type CookieConfig struct {
+	Domain         string
+	Path           string   // 默认 /api/user，只在刷新、注销等接口上发送
+	SameSite       string   // strict（默认）/ lax / none，前后端跨站部署时使用 none
+	Insecure       bool     // 本地 http 开发时设为 true，去掉 Secure 属性；SameSite=none 时无效
+	AllowedOrigins []string // 允许携带 Cookie 调用刷新接口的跨站来源，如 https://app.example.com
+}
+
 type RedisConfig struct {
 	Host     string
 	Port     int
@@ -33,6 +61,111 @@ type RedisConfig struct {
 	UserName string
 }
 
+// JWTConfig JWT 签名配置
+type JWTConfig struct {
+	Issuer      string         // 签发者 iss，为空则不写入也不校验
+	ActiveKID   string         // 当前用于签名的密钥 kid
+	GracePeriod time.Duration  // 密钥退役后仍可用于验签的宽限期，默认等于刷新令牌有效期
+	Keys        []JWTKeyConfig // 密钥列表（含已退役密钥）
+}
+
+// JWTKeyConfig 单个签名密钥
+type JWTKeyConfig struct {
+	KID            string // 密钥 ID，写入令牌头部 kid
+	Alg            string // HS256 / RS256 / EdDSA
+	SecretEnv      string // 从该环境变量读取 HS256 共享密钥
+	SecretFile     string // 环境变量未设置时从该文件读取 HS256 共享密钥；密钥不能写在配置文件中
+	PrivateKeyFile string // RS256 / EdDSA 私钥 PEM 文件路径
+	RetiredAt      string // 退役时间 (RFC3339)，为空表示未退役
+}
+
+// RBACConfig 角色权限配置
+type RBACConfig struct {
+	Roles map[string][]string // 角色 -> 权限列表，支持 "*" 与 "article:*" 通配
+}
+
+// LoginConfig 登录防暴力破解配置
+type LoginConfig struct {
+	MaxAccountFailures int           // 同一账号连续失败多少次后锁定
+	MaxIPFailures      int           // 同一 IP 连续失败多少次后锁定
+	FailureWindow      time.Duration // 失败计数的统计窗口
+	BackoffBase        time.Duration // 每次失败后的退避基数，按 2^n 递增
+	BackoffMax         time.Duration // 退避上限
+	LockoutDuration    time.Duration // 锁定时长
+	DefaultCountryCode string        // 手机号未带国际区号时使用的默认区号，如 86
+	AllowLegacyGet     bool          // 是否保留已废弃的 GET /api/user/login，下个版本移除
+}
+
+// MailConfig 邮件配置
+type MailConfig struct {
+	Driver           string // smtp / file，默认 file
+	Dir              string // file 驱动的输出目录，为空则只打印日志
+	From             string
+	Host             string
+	Port             int
+	Username         string
+	Password         string
+	BaseURL          string // 邮件中链接的前缀
+	UnverifiedStatus string // 注册后未验证邮箱的用户状态，为空则注册即为 active
+}
+
+// PasswordConfig 密码策略配置
+type PasswordConfig struct {
+	MinLength           int
+	MaxLength           int
+	MinClasses          int    // 至少包含的字符类别数（小写、大写、数字、符号）
+	CommonPasswordsFile string // 常见/已泄露密码列表，每行一个
+}
+
+// OIDCConfig 第三方登录 (OpenID Connect) 配置
+type OIDCConfig struct {
+	Providers []OIDCProviderConfig
+}
+
+// OIDCProviderConfig 身份提供方配置
+type OIDCProviderConfig struct {
+	Name            string // 路由中使用的名称，如 /api/user/oidc/{name}/login
+	Issuer          string
+	ClientID        string
+	ClientSecret    string
+	ClientSecretEnv string // 优先从环境变量读取
+	RedirectURL     string // 回调地址，指向 /api/user/oidc/{name}/callback
+	Scopes          []string
+	AutoCreate      bool // 没有匹配的用户时自动创建
+}
+
+// AuditConfig 认证审计日志配置
+type AuditConfig struct {
+	Retention     time.Duration // 保留期，超过后清理，为 0 表示永久保留
+	PurgeInterval time.Duration // 清理间隔，默认 24h
+}
+
+// LoginAlertConfig 新设备与异常登录提醒配置
+type LoginAlertConfig struct {
+	Enabled   bool
+	GeoIPFile string // 本地 GeoIP CSV（网段,国家代码），为空则不判断国家
+	Notifier  string // mail / log（默认）
+	StepUp    bool   // 异常登录需要二次验证：开启两步验证的用户使用动态码，否则使用邮件验证码
+}
+
+// RegisterConfig 注册配置
+type RegisterConfig struct {
+	Mode             string        // open（默认）/ invite（需要邀请码）/ closed（暂停注册）
+	InviteExpiration time.Duration // 邀请码默认有效期，默认 168h
+	UserMaxUses      int           // 普通用户创建的邀请码最多可使用次数，默认 5
+	UserMaxActive    int           // 普通用户同时有效的邀请码数量上限，默认 5
+}
+
+// SearchConfig 文章搜索配置
+type SearchConfig struct {
+	Backend string // mysql（默认，FULLTEXT ngram 索引）/ memory（进程内倒排索引，启动时从数据库重建）
+}
+
+// ArticleConfig 文章配置
+type ArticleConfig struct {
+	RevisionRetention int // 每篇文章保留的修订版本数，超出后删除最早的版本，为 0 表示全部保留
+}
+
 var Conf *Config
 
 func InitConfig() {
@@ -25,7 +25,9 @@ func InitDB() {
 	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{
 		// 配置GORM以仅打印API执行的SQL语句
 		PrepareStmt: true,
-		Logger:      logger.Default.LogMode(logger.Info),
+		// 唯一索引冲突等错误转换为 gorm.ErrDuplicatedKey
+		TranslateError: true,
+		Logger:         logger.Default.LogMode(logger.Info),
 	})
 	if err != nil {
 		panic("数据库连接失败: " + err.Error())
@@ -45,6 +47,24 @@ func InitDB() {
 	if err != nil {
 		return
 	}
+	if err = model.AutoMigrateMFA(db); err != nil {
+		panic("两步验证表自动迁移失败: " + err.Error())
+	}
+	if err = model.AutoMigrateToken(db); err != nil {
+		panic("个人访问令牌表自动迁移失败: " + err.Error())
+	}
+	if err = model.AutoMigrateIdentity(db); err != nil {
+		panic("第三方身份表自动迁移失败: " + err.Error())
+	}
+	if err = model.AutoMigrateInvite(db); err != nil {
+		panic("邀请码表自动迁移失败: " + err.Error())
+	}
+	if err = model.AutoMigrateRevision(db); err != nil {
+		panic("文章修订记录表自动迁移失败: " + err.Error())
+	}
+	if err = model.AutoMigrateAuthEvent(db); err != nil {
+		panic("审计日志表自动迁移失败: " + err.Error())
+	}
 	//model.AutoMigrateEmoji(db) // 创建表情包表结构
 	DB = db
 }
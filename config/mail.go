@@ -0,0 +1,32 @@
+package config
+
+import (
+	"TestGin/util"
+	"fmt"
+)
+
+var mailer util.Mailer
+
+// InitMailer 根据配置创建邮件发送器
+func InitMailer() util.Mailer {
+	m := Conf.Mail
+	switch m.Driver {
+	case "smtp":
+		mailer = &util.SMTPMailer{
+			Host:     m.Host,
+			Port:     m.Port,
+			Username: m.Username,
+			Password: m.Password,
+			From:     m.From,
+		}
+	default:
+		mailer = &util.FileMailer{Dir: m.Dir, From: m.From}
+	}
+	fmt.Printf("邮件发送器: %T\n", mailer)
+	return mailer
+}
+
+// GetMailer 获取邮件发送器
+func GetMailer() util.Mailer {
+	return mailer
+}
@@ -0,0 +1,45 @@
+package config
+
+import (
+	"TestGin/util"
+	"os"
+)
+
+var oidcProviders = map[string]*util.OIDCProvider{}
+
+// InitOIDC 根据配置创建身份提供方，元数据在首次使用时获取
+func InitOIDC() map[string]*util.OIDCProvider {
+	providers := make(map[string]*util.OIDCProvider, len(Conf.OIDC.Providers))
+	for _, pc := range Conf.OIDC.Providers {
+		secret := pc.ClientSecret
+		if pc.ClientSecretEnv != "" {
+			if v := os.Getenv(pc.ClientSecretEnv); v != "" {
+				secret = v
+			}
+		}
+		providers[pc.Name] = &util.OIDCProvider{
+			Name:         pc.Name,
+			Issuer:       pc.Issuer,
+			ClientID:     pc.ClientID,
+			ClientSecret: secret,
+			RedirectURL:  pc.RedirectURL,
+			Scopes:       pc.Scopes,
+		}
+	}
+	oidcProviders = providers
+	return providers
+}
+
+// GetOIDCProvider 按名称获取身份提供方
+func GetOIDCProvider(name string) (*util.OIDCProvider, *OIDCProviderConfig, bool) {
+	p, ok := oidcProviders[name]
+	if !ok {
+		return nil, nil, false
+	}
+	for i := range Conf.OIDC.Providers {
+		if Conf.OIDC.Providers[i].Name == name {
+			return p, &Conf.OIDC.Providers[i], true
+		}
+	}
+	return nil, nil, false
+}
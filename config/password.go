@@ -0,0 +1,36 @@
+package config
+
+import (
+	"TestGin/util"
+	"log"
+)
+
+var passwordPolicy = &util.PasswordPolicy{MinLength: 8, MaxLength: 72, MinClasses: 2}
+
+// InitPasswordPolicy 根据配置创建密码策略，未配置的项使用默认值
+func InitPasswordPolicy() *util.PasswordPolicy {
+	p := Conf.Password
+	if p.MinLength > 0 {
+		passwordPolicy.MinLength = p.MinLength
+	}
+	if p.MaxLength > 0 {
+		passwordPolicy.MaxLength = p.MaxLength
+	}
+	if p.MinClasses > 0 {
+		passwordPolicy.MinClasses = p.MinClasses
+	}
+	if p.CommonPasswordsFile != "" {
+		list, err := util.LoadCommonPasswords(p.CommonPasswordsFile)
+		if err != nil {
+			log.Printf("加载常见密码列表失败: %v", err)
+		} else {
+			passwordPolicy.Common = list
+		}
+	}
+	return passwordPolicy
+}
+
+// GetPasswordPolicy 获取密码策略
+func GetPasswordPolicy() *util.PasswordPolicy {
+	return passwordPolicy
+}
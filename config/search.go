@@ -0,0 +1,48 @@
+package config
+
+import (
+	"TestGin/model"
+	"TestGin/search"
+	"log"
+)
+
+var searcher search.Searcher = search.NewMemoryIndex()
+
+// InitSearch 创建文章搜索后端，需在 InitDB 之后调用；MySQL 全文索引创建失败时退回内存索引
+func InitSearch() {
+	if Conf.Search.Backend != "memory" {
+		s, err := search.NewMySQLSearcher(DB)
+		if err == nil {
+			searcher = s
+			return
+		}
+		log.Printf("创建全文索引失败，改用内存索引: %v", err)
+	}
+	index := search.NewMemoryIndex()
+	var articles []model.Article
+	if err := DB.Find(&articles).Error; err != nil {
+		log.Printf("加载文章失败，搜索索引为空: %v", err)
+	}
+	for _, a := range articles {
+		_ = index.Index(ArticleDocument(a))
+	}
+	searcher = index
+	log.Printf("内存搜索索引已建立，共 %d 篇文章", len(articles))
+}
+
+// GetSearcher 获取文章搜索后端
+func GetSearcher() search.Searcher {
+	return searcher
+}
+
+// ArticleDocument 文章转换为搜索文档
+func ArticleDocument(a model.Article) search.Document {
+	return search.Document{
+		ID:        a.ID,
+		UserID:    a.UserID,
+		Title:     a.Title,
+		Content:   a.Content,
+		Status:    int(a.Status),
+		CreatedAt: a.CreatedAt,
+	}
+}
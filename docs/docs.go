@@ -15,9 +15,35 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/.well-known/jwks.json": {
+            "get": {
+                "description": "返回 RS256/EdDSA 公钥集合 (JWKS)，HS256 密钥不会公开",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "登录"
+                ],
+                "summary": "获取 JWT 验签公钥",
+                "responses": {
+                    "200": {
+                        "description": "公钥集合",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.JWKSet"
+                        }
+                    }
+                }
+            }
+        },
         "/api/article/add": {
             "post": {
-                "description": "添加文章",
+                "description": "添加文章，新文章为草稿状态；可同时设置标签（最多 10 个，不存在时创建）与分类（最多 5 个）",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
                     "文章"
                 ],
@@ -36,7 +62,7 @@ const docTemplate = `{
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/model.Article"
+                            "$ref": "#/definitions/model.ArticleRequest"
                         }
                     }
                 ],
@@ -46,25 +72,27 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/middleware.Response"
                         }
+                    },
+                    "400": {
+                        "description": "分类不存在",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Response"
+                        }
                     }
                 }
             }
         },
-        "/api/article/get/:id": {
+        "/api/article/categories": {
             "get": {
-                "description": "查询文章",
+                "description": "按排序值排列的全部分类，children 为下级分类",
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
-                    "文章"
+                    "标签与分类"
                 ],
-                "summary": "查询文章",
+                "summary": "分类树",
                 "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "文章ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
                     {
                         "type": "string",
                         "description": "Bearer Token",
@@ -75,21 +103,27 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "文章信息",
+                        "description": "分类树",
                         "schema": {
-                            "$ref": "#/definitions/model.ArticleResponse"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.CategoryNode"
+                            }
                         }
                     }
                 }
-            }
-        },
-        "/api/article/{id}": {
-            "put": {
-                "description": "更新文章",
+            },
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
-                    "文章"
+                    "标签与分类"
                 ],
-                "summary": "更新文章",
+                "summary": "创建分类",
                 "parameters": [
                     {
                         "type": "string",
@@ -99,25 +133,24 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "type": "integer",
-                        "description": "文章ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "请求体",
+                        "description": "名称、上级分类与排序值",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/model.Article"
+                            "$ref": "#/definitions/model.CategoryRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "更新成功返回",
+                        "description": "分类",
+                        "schema": {
+                            "$ref": "#/definitions/model.Category"
+                        }
+                    },
+                    "409": {
+                        "description": "同级分类重名",
                         "schema": {
                             "$ref": "#/definitions/middleware.Response"
                         }
@@ -125,21 +158,20 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/article/{uuid}/status": {
+        "/api/article/categories/{id}": {
             "put": {
-                "description": "更新文章状态",
+                "description": "重命名、调整排序或移动到其他上级分类下，不能移动到自己的下级分类下",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
-                    "文章"
+                    "标签与分类"
                 ],
-                "summary": "更新文章状态",
+                "summary": "修改分类",
                 "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "文章ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
                     {
                         "type": "string",
                         "description": "Bearer Token",
@@ -148,108 +180,91 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "description": "请求体 (status: 0=Draft, 1=Pending, 2=Published)",
+                        "type": "integer",
+                        "description": "分类ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "名称、上级分类与排序值",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "type": "integer"
+                            "$ref": "#/definitions/model.CategoryRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "更新成功返回",
+                        "description": "分类",
+                        "schema": {
+                            "$ref": "#/definitions/model.Category"
+                        }
+                    },
+                    "409": {
+                        "description": "同级分类重名或形成循环",
                         "schema": {
                             "$ref": "#/definitions/middleware.Response"
                         }
                     }
                 }
-            }
-        },
-        "/api/articles/delete/:id": {
+            },
             "delete": {
-                "description": "删除文章",
+                "description": "有下级分类时返回 409；文章与该分类的关联一并删除，文章本身不受影响",
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
-                    "文章"
+                    "标签与分类"
                 ],
-                "summary": "删除文章",
+                "summary": "删除分类",
                 "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "文章ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
                     {
                         "type": "string",
                         "description": "Bearer Token",
                         "name": "Authorization",
                         "in": "header",
                         "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "分类ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "文章信息",
+                        "description": "成功",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/middleware.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "存在下级分类",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Response"
                         }
                     }
                 }
             }
         },
-        "/api/comment/add": {
-            "post": {
-                "description": "添加评论",
-                "consumes": [
-                    "multipart/form-data"
-                ],
+        "/api/article/delete/{id}": {
+            "delete": {
+                "description": "删除文章",
                 "tags": [
-                    "评论"
+                    "文章"
                 ],
-                "summary": "添加评论",
+                "summary": "删除文章",
                 "parameters": [
-                    {
-                        "type": "string",
-                        "description": "评论内容",
-                        "name": "content",
-                        "in": "formData",
-                        "required": true
-                    },
-                    {
-                        "type": "integer",
-                        "description": "所属帖子ID",
-                        "name": "postId",
-                        "in": "formData",
-                        "required": true
-                    },
-                    {
-                        "type": "integer",
-                        "description": "评论用户ID",
-                        "name": "userId",
-                        "in": "formData",
-                        "required": true
-                    },
-                    {
-                        "type": "integer",
-                        "description": "父评论ID",
-                        "name": "parentId",
-                        "in": "formData"
-                    },
                     {
                         "type": "integer",
-                        "description": "资源类型 image/video",
-                        "name": "type",
-                        "in": "formData",
-                        "required": true
-                    },
-                    {
-                        "type": "file",
-                        "description": "上传文件",
-                        "name": "files",
-                        "in": "formData",
+                        "description": "文章ID",
+                        "name": "id",
+                        "in": "path",
                         "required": true
                     },
                     {
@@ -262,7 +277,13 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "{\"code\":200,\"data\":{},\"msg\":\"操作成功\"}",
+                        "description": "文章信息",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "不是文章作者",
                         "schema": {
                             "$ref": "#/definitions/middleware.Response"
                         }
@@ -270,19 +291,19 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/comment/list": {
+        "/api/article/get/{id}": {
             "get": {
-                "description": "获取评论列表",
+                "description": "已发布的文章所有人可见；未发布的文章只有作者与拥有 article:moderate 或 article:publish 权限的角色可见",
                 "tags": [
-                    "评论"
+                    "文章"
                 ],
-                "summary": "获取评论列表",
+                "summary": "查询文章",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "帖子ID",
-                        "name": "postId",
-                        "in": "query",
+                        "description": "文章ID",
+                        "name": "id",
+                        "in": "path",
                         "required": true
                     },
                     {
@@ -295,7 +316,13 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "{\"code\":200,\"data\":{},\"msg\":\"操作成功\"}",
+                        "description": "文章信息",
+                        "schema": {
+                            "$ref": "#/definitions/model.ArticleResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "文章不存在或无权查看",
                         "schema": {
                             "$ref": "#/definitions/middleware.Response"
                         }
@@ -303,15 +330,16 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/user/add": {
-            "post": {
+        "/api/article/list": {
+            "get": {
+                "description": "按作者、状态、标签、分类、创建时间筛选，按最新 (newest)、最近更新 (updated)、热度 (popular) 排序，使用 next_cursor 翻页。\n默认只返回已发布的文章；其他状态只能查看自己的文章，拥有 article:moderate 或 article:publish 权限的角色不受限制",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "用户"
+                    "文章"
                 ],
-                "summary": "添加用户",
+                "summary": "文章列表",
                 "parameters": [
                     {
                         "type": "string",
@@ -321,34 +349,86 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "description": "用户信息",
-                        "name": "user",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/model.User"
-                        }
+                        "type": "integer",
+                        "description": "作者ID",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "状态 (draft、pending、published、rejected、archived)，默认 published",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "标签",
+                        "name": "tag",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "分类ID，包含下级分类",
+                        "name": "category_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "创建时间起 (RFC3339)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "创建时间止 (RFC3339)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "排序方式 (newest、updated、popular)，默认 newest",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "上一页返回的 next_cursor",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "每页条数，默认 20，最多 100",
+                        "name": "size",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "成功",
+                        "description": "文章列表",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/model.ArticlePage"
+                        }
+                    },
+                    "403": {
+                        "description": "无权查看他人未发布的文章",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Response"
                         }
                     }
                 }
             }
         },
-        "/api/user/get/:id": {
+        "/api/article/search": {
             "get": {
+                "description": "在已发布文章的标题与内容中搜索，按相关度排序；标题与摘要中的命中词用 \u003cem\u003e\u003c/em\u003e 包裹，其余内容已做 HTML 转义",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "用户"
+                    "文章"
                 ],
-                "summary": "获取用户信息",
+                "summary": "搜索文章",
                 "parameters": [
                     {
                         "type": "string",
@@ -358,33 +438,63 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "type": "integer",
-                        "description": "用户ID",
-                        "name": "id",
-                        "in": "path",
+                        "type": "string",
+                        "description": "关键词",
+                        "name": "q",
+                        "in": "query",
                         "required": true
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "成功",
-                        "schema": {
-                            "type": "string"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "作者ID",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "创建时间起 (RFC3339)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "创建时间止 (RFC3339)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "页码，默认 1，最多 50",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "每页条数，默认 10，最多 50",
+                        "name": "size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "搜索结果",
+                        "schema": {
+                            "$ref": "#/definitions/search.Result"
                         }
                     }
                 }
             }
         },
-        "/api/user/list": {
+        "/api/article/tags": {
             "get": {
-                "description": "返回所有用户信息",
+                "description": "返回至少有一篇文章的标签及文章数，按文章数倒序；文章数包含未发布的文章",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "用户"
+                    "标签与分类"
                 ],
-                "summary": "获取用户列表",
+                "summary": "标签云",
                 "parameters": [
                     {
                         "type": "string",
@@ -392,64 +502,67 @@ const docTemplate = `{
                         "name": "Authorization",
                         "in": "header",
                         "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "返回数量，默认 100，最多 500",
+                        "name": "limit",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "用户列表",
+                        "description": "标签列表",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/model.UserResponse"
+                                "$ref": "#/definitions/model.Tag"
                             }
                         }
                     }
                 }
             }
         },
-        "/api/user/login": {
-            "get": {
-                "description": "登录",
+        "/api/article/tags/merge": {
+            "post": {
+                "description": "把 sources 标签下的文章归入 target（不存在时创建），删除 sources 并重新计算 target 的文章数",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "登录"
+                    "标签与分类"
                 ],
-                "summary": "登录",
+                "summary": "合并标签",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "用户邮箱",
-                        "name": "Email",
-                        "in": "query",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "账号",
-                        "name": "Account",
-                        "in": "query",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "手机号",
-                        "name": "Phone",
-                        "in": "query",
+                        "description": "Bearer Token",
+                        "name": "Authorization",
+                        "in": "header",
                         "required": true
                     },
                     {
-                        "type": "string",
-                        "description": "用户密码",
-                        "name": "Password",
-                        "in": "query",
-                        "required": true
+                        "description": "来源标签与目标标签",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.MergeTagsRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "成功",
+                        "description": "合并后的标签",
+                        "schema": {
+                            "$ref": "#/definitions/model.Tag"
+                        }
+                    },
+                    "404": {
+                        "description": "来源标签不存在",
                         "schema": {
                             "$ref": "#/definitions/middleware.Response"
                         }
@@ -457,9 +570,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/user/refresh": {
-            "post": {
-                "description": "刷新token",
+        "/api/article/tags/{id}": {
+            "put": {
+                "description": "新名称已被其他标签使用时返回 409，请改用合并",
                 "consumes": [
                     "application/json"
                 ],
@@ -467,45 +580,91 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "登录"
+                    "标签与分类"
                 ],
-                "summary": "刷新token",
+                "summary": "重命名标签",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "刷新token",
-                        "name": "refresh_token",
-                        "in": "formData",
+                        "description": "Bearer Token",
+                        "name": "Authorization",
+                        "in": "header",
                         "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "标签ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "新名称",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.RenameTagRequest"
+                        }
                     }
                 ],
-                "responses": {}
+                "responses": {
+                    "200": {
+                        "description": "标签",
+                        "schema": {
+                            "$ref": "#/definitions/model.Tag"
+                        }
+                    },
+                    "409": {
+                        "description": "名称已存在",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Response"
+                        }
+                    }
+                }
             }
         },
-        "/api/user/update/password": {
-            "post": {
-                "description": "更新用户密码",
-                "produces": [
-                    "application/json"
-                ],
+        "/api/article/update/{id}": {
+            "put": {
+                "description": "更新文章，标题或内容变化时生成新的修订版本",
                 "tags": [
-                    "用户"
+                    "文章"
                 ],
-                "summary": "更新用户密码",
+                "summary": "更新文章",
                 "parameters": [
                     {
-                        "description": "用户信息",
-                        "name": "user",
+                        "type": "string",
+                        "description": "Bearer Token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "文章ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "请求体，省略的字段保持不变",
+                        "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/model.User"
+                            "$ref": "#/definitions/model.ArticleRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "成功",
+                        "description": "更新成功返回",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "不是文章作者",
                         "schema": {
                             "$ref": "#/definitions/middleware.Response"
                         }
@@ -513,30 +672,44 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/user/update/user": {
-            "post": {
-                "description": "更新用户数据",
+        "/api/article/{id}/history": {
+            "get": {
+                "description": "作者本人或拥有 article:moderate 权限的角色查看，按时间倒序",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "用户"
+                    "文章"
                 ],
-                "summary": "更新用户数据",
+                "summary": "文章状态变更记录",
                 "parameters": [
                     {
-                        "description": "用户信息",
-                        "name": "user",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/model.User"
-                        }
+                        "type": "integer",
+                        "description": "文章ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Bearer Token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "成功",
+                        "description": "变更记录",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.ArticleStatusHistory"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "不是文章作者",
                         "schema": {
                             "$ref": "#/definitions/middleware.Response"
                         }
@@ -544,197 +717,2642 @@ const docTemplate = `{
                 }
             }
         },
-        "/ws": {
+        "/api/article/{id}/revisions": {
             "get": {
-                "description": "通过WebSocket建立连接",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "作者本人或拥有 article:moderate 权限的角色查看，按版本倒序，不含正文",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "websocket"
+                    "文章修订"
+                ],
+                "summary": "文章修订记录",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer Token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "文章ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "WebSocket连接",
                 "responses": {
                     "200": {
-                        "description": "连接成功",
+                        "description": "版本列表",
                         "schema": {
-                            "type": "string"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.ArticleRevision"
+                            }
                         }
                     },
-                    "500": {
-                        "description": "连接失败",
+                    "403": {
+                        "description": "不是文章作者",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/middleware.Response"
                         }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "middleware.Response": {
-            "type": "object",
-            "properties": {
-                "code": {
-                    "description": "自定义状态码",
-                    "type": "integer"
-                },
-                "data": {
-                    "description": "返回数据"
-                },
-                "message": {
-                    "description": "提示信息",
-                    "type": "string"
-                },
-                "time": {
-                    "type": "string"
+        },
+        "/api/article/{id}/revisions/diff": {
+            "get": {
+                "description": "mode=unified 返回按行比较的统一差异（diff -u 格式）；mode=word 返回逐词差异片段，中文按字比较。标题始终逐词比较",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "文章修订"
+                ],
+                "summary": "比较文章版本",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer Token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "文章ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "旧版本号",
+                        "name": "from",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "新版本号",
+                        "name": "to",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "unified（默认）或 word",
+                        "name": "mode",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "差异",
+                        "schema": {
+                            "$ref": "#/definitions/model.RevisionDiff"
+                        }
+                    },
+                    "404": {
+                        "description": "版本不存在",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Response"
+                        }
+                    }
                 }
             }
         },
-        "model.Article": {
+        "/api/article/{id}/revisions/{version}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "文章修订"
+                ],
+                "summary": "查看文章版本",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer Token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "文章ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "版本号",
+                        "name": "version",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "版本内容",
+                        "schema": {
+                            "$ref": "#/definitions/model.ArticleRevision"
+                        }
+                    },
+                    "404": {
+                        "description": "版本不存在",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/article/{id}/revisions/{version}/restore": {
+            "post": {
+                "description": "用旧版本的标题与内容覆盖文章，并生成一个新版本（restored_from 为来源版本），不会删除之后的版本",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "文章修订"
+                ],
+                "summary": "恢复文章版本",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer Token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "文章ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "要恢复的版本号",
+                        "name": "version",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "新生成的版本",
+                        "schema": {
+                            "$ref": "#/definitions/model.ArticleRevision"
+                        }
+                    },
+                    "404": {
+                        "description": "版本不存在",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/article/{id}/status": {
+            "put": {
+                "description": "需要 article:status 权限。按状态机流转：作者可以提交审核、撤回、归档；article:publish 审核通过或驳回（驳回需填写原因）；article:moderate 可以归档、撤下已发布文章。\n不允许的流转返回 409，没有权限返回 403；每次变更记录在 article_status_history 中",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "文章"
+                ],
+                "summary": "更新文章状态",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "文章ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Bearer Token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "目标状态 (draft、pending、published、rejected、archived) 与原因",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.UpdateArticleStatusRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "变更后的文章",
+                        "schema": {
+                            "$ref": "#/definitions/model.ArticleResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "没有执行该流转的权限",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "不允许的状态流转",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/audit/auth-events": {
+            "get": {
+                "description": "管理员按用户、事件类型与时间范围查询，按时间倒序",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "审计"
+                ],
+                "summary": "认证审计日志",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer Token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "用户UUID",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "事件类型",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "开始时间 (RFC3339)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "结束时间 (RFC3339)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "页码，默认 1",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "每页条数，默认 20，最多 200",
+                        "name": "size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "事件列表",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.AuthEvent"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/comment/add": {
+            "post": {
+                "description": "添加评论",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "tags": [
+                    "评论"
+                ],
+                "summary": "添加评论",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "评论内容",
+                        "name": "content",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "所属帖子ID",
+                        "name": "postId",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "父评论ID",
+                        "name": "parentId",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "资源类型 image/video",
+                        "name": "type",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "上传文件",
+                        "name": "files",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Bearer Token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "{\"code\":200,\"data\":{},\"msg\":\"操作成功\"}",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/comment/list": {
+            "get": {
+                "description": "获取评论列表",
+                "tags": [
+                    "评论"
+                ],
+                "summary": "获取评论列表",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "帖子ID",
+                        "name": "postId",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Bearer Token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "{\"code\":200,\"data\":{},\"msg\":\"操作成功\"}",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/add": {
+            "post": {
+                "description": "注册新用户。register.mode 为 invite 时必须提供 invite_code，为 closed 时暂停注册；账号、用户名、邮箱已被占用时返回 409",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "用户"
+                ],
+                "summary": "添加用户",
+                "parameters": [
+                    {
+                        "description": "用户信息",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.RegisterRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "暂停注册或需要邀请码",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "账号、用户名或邮箱已被占用",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/auth-events": {
+            "get": {
+                "description": "当前用户的登录、刷新、注销、会话撤销、密码修改等记录，按时间倒序；需要登录会话，个人访问令牌返回 403",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "审计"
+                ],
+                "summary": "我的认证记录",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer Token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "事件类型",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "开始时间 (RFC3339)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "结束时间 (RFC3339)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "页码，默认 1",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "每页条数，默认 20，最多 200",
+                        "name": "size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "事件列表",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.AuthEvent"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/disable/{id}": {
+            "post": {
+                "description": "禁用用户并立即撤销其所有会话。设置 until 为临时禁用，到期后自动解除",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "用户管理"
+                ],
+                "summary": "禁用用户",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer Token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "用户UUID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "原因与到期时间",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.DisableUserRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/email/resend": {
+            "post": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "账号"
+                ],
+                "summary": "重新发送验证邮件",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer Token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Response"
+                        }
+                    },
+                    "429": {
+                        "description": "发送过于频繁",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/email/verify": {
+            "post": {
+                "description": "使用邮件中的令牌验证邮箱，未验证状态的用户恢复为 active，重新登录后获得完整权限",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "账号"
+                ],
+                "summary": "验证邮箱",
+                "parameters": [
+                    {
+                        "description": "令牌",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.VerifyEmailRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/enable/{id}": {
+            "post": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "用户管理"
+                ],
+                "summary": "解除禁用",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer Token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "用户UUID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/get/:id": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "用户"
+                ],
+                "summary": "获取用户信息",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer Token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "用户ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/identities": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "第三方登录"
+                ],
+                "summary": "第三方身份列表",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer Token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "身份列表",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.UserIdentity"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/identities/{id}": {
+            "delete": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "第三方登录"
+                ],
+                "summary": "解除第三方身份",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer Token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "身份ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/identities/{provider}": {
+            "post": {
+                "description": "返回身份提供方授权地址，客户端跳转后在回调中完成关联",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "第三方登录"
+                ],
+                "summary": "关联第三方身份",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer Token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "身份提供方名称",
+                        "name": "provider",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "authorization_url",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/impersonate/{id}": {
+            "post": {
+                "description": "以目标用户身份签发 10 分钟有效的访问令牌（带 act 声明，不签发刷新令牌），用于复现用户反馈的问题。\n使用该令牌的响应带有 X-Impersonated-By 头；不能修改密码、资料、会话、令牌等，写操作记录到审计日志",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "用户管理"
+                ],
+                "summary": "模拟登录",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer Token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "用户UUID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "原因",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.ImpersonateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "access_token",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/invites": {
+            "get": {
+                "description": "当前用户创建的邀请码；拥有 invite:manage 权限时 all=true 返回所有邀请码",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "邀请码"
+                ],
+                "summary": "邀请码列表",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer Token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "是否返回所有用户的邀请码",
+                        "name": "all",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "邀请码列表",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.InviteCode"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "普通用户的使用次数与同时有效数量受 register.usermaxuses / usermaxactive 限制",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "邀请码"
+                ],
+                "summary": "创建邀请码",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer Token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "使用次数与有效天数",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.CreateInviteRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "邀请码",
+                        "schema": {
+                            "$ref": "#/definitions/model.InviteCode"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/invites/{id}": {
+            "delete": {
+                "description": "作废自己创建的邀请码，拥有 invite:manage 权限时可以作废任意邀请码；已注册的用户不受影响",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "邀请码"
+                ],
+                "summary": "作废邀请码",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer Token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "邀请码ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/list": {
+            "get": {
+                "description": "返回所有用户信息",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "用户"
+                ],
+                "summary": "获取用户列表",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer Token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "用户列表",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.UserResponse"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/login": {
+            "get": {
+                "description": "密码出现在查询串中会被记录到访问日志，请改用 POST /api/user/login。仅在配置 login.allowlegacyget 开启时可用",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "登录"
+                ],
+                "summary": "登录（已废弃）",
+                "deprecated": true,
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "用户邮箱",
+                        "name": "Email",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "账号",
+                        "name": "Account",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "手机号",
+                        "name": "Phone",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "用户密码",
+                        "name": "Password",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Response"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "使用 account、email、phone 之一加密码登录。访问令牌在 Authorization 响应头中，刷新令牌写入 refresh_token Cookie",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "登录"
+                ],
+                "summary": "登录",
+                "parameters": [
+                    {
+                        "description": "登录信息",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.LoginRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功；开启两步验证时返回 mfa_token，需调用 /api/user/login/mfa",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Response"
+                        }
+                    },
+                    "423": {
+                        "description": "多次失败被临时锁定 (code=42301)",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Response"
+                        }
+                    },
+                    "429": {
+                        "description": "失败后退避中 (code=42901)",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/login/mfa": {
+            "post": {
+                "description": "使用登录返回的 mfa_token 加动态码或恢复码换取令牌，mfa_token 提交一次即作废，动态码错误需重新登录",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "登录"
+                ],
+                "summary": "两步验证登录",
+                "parameters": [
+                    {
+                        "description": "临时令牌与动态码",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.MFALoginRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Response"
+                        }
+                    },
+                    "423": {
+                        "description": "多次失败被临时锁定 (code=42301)",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Response"
+                        }
+                    },
+                    "429": {
+                        "description": "失败后退避中 (code=42901)",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/login/step-up": {
+            "post": {
+                "description": "新设备或新网络登录时，使用登录返回的 step_up_token 加邮件验证码换取令牌",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "登录"
+                ],
+                "summary": "异常登录二次验证",
+                "parameters": [
+                    {
+                        "description": "临时令牌与验证码",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.StepUpLoginRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Response"
+                        }
+                    },
+                    "423": {
+                        "description": "多次失败被临时锁定 (code=42301)",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Response"
+                        }
+                    },
+                    "429": {
+                        "description": "失败后退避中 (code=42901)",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/logout": {
+            "post": {
+                "description": "注销当前会话：访问令牌加入黑名单直至过期，刷新令牌失效",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "登录"
+                ],
+                "summary": "注销",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer Token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/mfa/confirm": {
+            "post": {
+                "description": "提交认证器 App 上的动态码完成绑定，返回一次性恢复码（只显示这一次）",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "两步验证"
+                ],
+                "summary": "确认绑定两步验证",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer Token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "动态码",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.MFACodeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "恢复码列表",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/mfa/disable": {
+            "post": {
+                "description": "提交动态码或恢复码关闭两步验证",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "两步验证"
+                ],
+                "summary": "关闭两步验证",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer Token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "动态码或恢复码",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.MFACodeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/mfa/enroll": {
+            "post": {
+                "description": "生成 TOTP 密钥，返回 otpauth 链接与二维码 PNG (Base64)，需调用 confirm 提交动态码后才会生效",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "两步验证"
+                ],
+                "summary": "绑定两步验证",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer Token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "已开启两步验证",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/oidc/{provider}/callback": {
+            "get": {
+                "description": "校验 state、用授权码换取令牌并验证 ID Token；按身份或已验证邮箱关联用户，没有则创建，然后签发令牌",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "第三方登录"
+                ],
+                "summary": "第三方登录回调",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "身份提供方名称",
+                        "name": "provider",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "授权码",
+                        "name": "code",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "state",
+                        "name": "state",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "邮箱冲突",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/oidc/{provider}/login": {
+            "get": {
+                "description": "跳转到身份提供方（OIDC 授权码 + PKCE），登录后回调 /api/user/oidc/{provider}/callback",
+                "tags": [
+                    "第三方登录"
+                ],
+                "summary": "第三方登录",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "身份提供方名称",
+                        "name": "provider",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "302": {
+                        "description": "跳转到身份提供方",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/password/forgot": {
+            "post": {
+                "description": "向邮箱发送重置密码链接。无论邮箱是否注册都返回成功，避免泄露账号是否存在",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "账号"
+                ],
+                "summary": "申请重置密码",
+                "parameters": [
+                    {
+                        "description": "邮箱",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.ForgotPasswordRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/password/reset": {
+            "post": {
+                "description": "使用邮件中的令牌设置新密码，令牌只能使用一次；成功后该用户所有会话失效",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "账号"
+                ],
+                "summary": "重置密码",
+                "parameters": [
+                    {
+                        "description": "令牌与新密码",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.ResetPasswordRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/refresh": {
+            "post": {
+                "description": "使用 refresh_token Cookie 刷新令牌：新的访问令牌在 Authorization 响应头中，刷新令牌与 CSRF 令牌同时轮换，新的 CSRF 令牌在响应体 csrf_token 中。\n需要在 X-CSRF-Token 头中带上 csrf_token Cookie（Path=/，页面脚本可读）的值，跨站来源返回 403",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "登录"
+                ],
+                "summary": "刷新token",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "csrf_token Cookie 的值",
+                        "name": "X-CSRF-Token",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "CSRF 校验失败",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/role/{id}": {
+            "post": {
+                "description": "角色必须在 rbac.roles 中定义；修改后撤销该用户所有会话，重新登录后新角色生效",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "用户管理"
+                ],
+                "summary": "修改用户角色",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer Token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "用户UUID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "角色",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.UpdateRoleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/sessions": {
+            "get": {
+                "description": "列出当前用户在各设备上的登录会话",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "会话"
+                ],
+                "summary": "登录会话列表",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer Token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "会话列表",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/api.SessionResponse"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/sessions/others": {
+            "delete": {
+                "description": "撤销除当前会话外的所有会话",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "会话"
+                ],
+                "summary": "撤销其他会话",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer Token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/sessions/{id}": {
+            "delete": {
+                "description": "撤销当前用户的指定会话，该设备需重新登录",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "会话"
+                ],
+                "summary": "撤销会话",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer Token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "会话ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/tokens": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "个人访问令牌"
+                ],
+                "summary": "个人访问令牌列表",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer Token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "令牌列表",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.TokenResponse"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "令牌只在创建时返回一次，库中只保存 SHA256。权限范围不能超出当前角色的权限",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "个人访问令牌"
+                ],
+                "summary": "创建个人访问令牌",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer Token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "令牌名称、权限范围、有效天数",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.CreateTokenRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "令牌（只显示这一次）",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/tokens/{id}": {
+            "delete": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "个人访问令牌"
+                ],
+                "summary": "撤销个人访问令牌",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer Token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "令牌ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/update/password": {
+            "post": {
+                "description": "校验旧密码后设置新密码，新密码需满足密码策略；成功后除当前会话外的所有会话失效",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "用户"
+                ],
+                "summary": "更新用户密码",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer Token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "旧密码与新密码",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.UpdatePasswordRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/update/user": {
+            "post": {
+                "description": "更新当前登录用户的资料（account、username、email、phone），只修改请求体中出现的字段",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "用户"
+                ],
+                "summary": "更新用户数据",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer Token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "用户信息",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.UpdateUserRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "账号、用户名或邮箱已被占用",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.Response"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "api.SessionResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "current": {
+                    "description": "是否为当前请求所用的会话",
+                    "type": "boolean"
+                },
+                "device_name": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "ip": {
+                    "type": "string"
+                },
+                "last_active": {
+                    "type": "string"
+                },
+                "user_agent": {
+                    "type": "string"
+                }
+            }
+        },
+        "middleware.JWK": {
+            "type": "object",
+            "properties": {
+                "alg": {
+                    "type": "string"
+                },
+                "crv": {
+                    "type": "string"
+                },
+                "e": {
+                    "type": "string"
+                },
+                "kid": {
+                    "type": "string"
+                },
+                "kty": {
+                    "type": "string"
+                },
+                "n": {
+                    "type": "string"
+                },
+                "use": {
+                    "type": "string"
+                },
+                "x": {
+                    "type": "string"
+                }
+            }
+        },
+        "middleware.JWKSet": {
+            "type": "object",
+            "properties": {
+                "keys": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/middleware.JWK"
+                    }
+                }
+            }
+        },
+        "middleware.Response": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "自定义状态码",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "返回数据"
+                },
+                "message": {
+                    "description": "提示信息",
+                    "type": "string"
+                },
+                "time": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.ArticlePage": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.ArticleResponse"
+                    }
+                },
+                "next_cursor": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.ArticleRequest": {
+            "type": "object",
+            "required": [
+                "tags"
+            ],
+            "properties": {
+                "category_ids": {
+                    "type": "array",
+                    "maxItems": 5,
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "content": {
+                    "type": "string"
+                },
+                "tags": {
+                    "type": "array",
+                    "maxItems": 10,
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "title": {
+                    "type": "string",
+                    "maxLength": 200
+                }
+            }
+        },
+        "model.ArticleResponse": {
+            "type": "object",
+            "properties": {
+                "categories": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Category"
+                    }
+                },
+                "content": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "integer"
+                },
+                "status_name": {
+                    "type": "string"
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "title": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                },
+                "views": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.ArticleRevision": {
+            "type": "object",
+            "properties": {
+                "article_id": {
+                    "type": "integer"
+                },
+                "author_id": {
+                    "type": "integer"
+                },
+                "content": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "restored_from": {
+                    "type": "integer"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "version": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.ArticleStatus": {
+            "type": "integer",
+            "enum": [
+                0,
+                1,
+                2,
+                3,
+                4
+            ],
+            "x-enum-comments": {
+                "Archived": "已归档",
+                "Draft": "草稿",
+                "Pending": "待审核",
+                "Published": "已发布",
+                "Rejected": "审核未通过"
+            },
+            "x-enum-varnames": [
+                "Draft",
+                "Pending",
+                "Published",
+                "Rejected",
+                "Archived"
+            ]
+        },
+        "model.ArticleStatusHistory": {
+            "type": "object",
+            "properties": {
+                "actor_id": {
+                    "type": "integer"
+                },
+                "article_id": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "from_name": {
+                    "type": "string"
+                },
+                "from_status": {
+                    "$ref": "#/definitions/model.ArticleStatus"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "to_name": {
+                    "type": "string"
+                },
+                "to_status": {
+                    "$ref": "#/definitions/model.ArticleStatus"
+                }
+            }
+        },
+        "model.AuthEvent": {
+            "type": "object",
+            "properties": {
+                "actor_id": {
+                    "type": "string"
+                },
+                "country": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "ip": {
+                    "type": "string"
+                },
+                "network": {
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "session_id": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "user_agent": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.Category": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "parent_id": {
+                    "type": "integer"
+                },
+                "sort": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.CategoryNode": {
+            "type": "object",
+            "properties": {
+                "children": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.CategoryNode"
+                    }
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "parent_id": {
+                    "type": "integer"
+                },
+                "sort": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.CategoryRequest": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "maxLength": 32
+                },
+                "parent_id": {
+                    "type": "integer"
+                },
+                "sort": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.CreateInviteRequest": {
+            "type": "object",
+            "properties": {
+                "expires_in_days": {
+                    "description": "默认 register.inviteexpiration",
+                    "type": "integer",
+                    "minimum": 1
+                },
+                "max_uses": {
+                    "description": "默认 1",
+                    "type": "integer",
+                    "minimum": 1
+                }
+            }
+        },
+        "model.CreateTokenRequest": {
+            "type": "object",
+            "required": [
+                "name",
+                "scopes"
+            ],
+            "properties": {
+                "expires_in_days": {
+                    "description": "为空表示永不过期",
+                    "type": "integer",
+                    "minimum": 1
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "scopes": {
+                    "description": "如 article:read、article:*",
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "model.DisableUserRequest": {
+            "type": "object",
+            "required": [
+                "reason"
+            ],
+            "properties": {
+                "reason": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "until": {
+                    "description": "RFC3339，为空表示永久禁用",
+                    "type": "string"
+                }
+            }
+        },
+        "model.ForgotPasswordRequest": {
+            "type": "object",
+            "required": [
+                "email"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.ImpersonateRequest": {
+            "type": "object",
+            "required": [
+                "reason"
+            ],
+            "properties": {
+                "reason": {
+                    "description": "如工单号，写入审计日志",
+                    "type": "string",
+                    "maxLength": 255
+                }
+            }
+        },
+        "model.InviteCode": {
             "type": "object",
             "properties": {
-                "content": {
-                    "description": "内容",
+                "code": {
                     "type": "string"
                 },
                 "created_at": {
-                    "description": "创建时间",
+                    "type": "string"
+                },
+                "creator_id": {
+                    "type": "integer"
+                },
+                "expires_at": {
                     "type": "string"
                 },
                 "id": {
-                    "description": "主键",
                     "type": "integer"
                 },
-                "status": {
-                    "description": "状态",
-                    "enum": [
-                        0,
-                        1,
-                        2
-                    ],
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/model.ArticleStatus"
-                        }
-                    ]
+                "max_uses": {
+                    "type": "integer"
                 },
-                "status_name": {
-                    "description": "状态名称",
+                "uses": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.LoginRequest": {
+            "type": "object",
+            "required": [
+                "password"
+            ],
+            "properties": {
+                "account": {
                     "type": "string"
                 },
-                "title": {
-                    "description": "标题",
+                "email": {
                     "type": "string"
                 },
-                "updated_at": {
-                    "description": "更新时间",
+                "password": {
                     "type": "string"
                 },
-                "user_id": {
-                    "description": "外键关联用户ID",
-                    "type": "integer"
+                "phone": {
+                    "type": "string"
                 }
             }
         },
-        "model.ArticleResponse": {
+        "model.MFACodeRequest": {
             "type": "object",
+            "required": [
+                "code"
+            ],
             "properties": {
-                "content": {
+                "code": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.MFALoginRequest": {
+            "type": "object",
+            "required": [
+                "code",
+                "mfa_token"
+            ],
+            "properties": {
+                "code": {
+                    "description": "动态码或恢复码",
                     "type": "string"
                 },
-                "created_at": {
+                "mfa_token": {
+                    "description": "密码校验通过后返回的临时令牌",
                     "type": "string"
+                }
+            }
+        },
+        "model.MergeTagsRequest": {
+            "type": "object",
+            "required": [
+                "sources",
+                "target"
+            ],
+            "properties": {
+                "sources": {
+                    "type": "array",
+                    "maxItems": 50,
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    }
                 },
-                "id": {
-                    "type": "integer"
+                "target": {
+                    "type": "string",
+                    "maxLength": 32
+                }
+            }
+        },
+        "model.RegisterRequest": {
+            "type": "object",
+            "required": [
+                "password",
+                "username"
+            ],
+            "properties": {
+                "account": {
+                    "type": "string",
+                    "maxLength": 100
                 },
-                "status": {
-                    "type": "integer"
+                "email": {
+                    "type": "string"
                 },
-                "status_name": {
+                "invite_code": {
+                    "description": "注册模式为 invite 时必填",
                     "type": "string"
                 },
-                "title": {
+                "password": {
                     "type": "string"
                 },
-                "updated_at": {
+                "phone": {
                     "type": "string"
                 },
-                "user_id": {
-                    "type": "integer"
+                "username": {
+                    "type": "string",
+                    "maxLength": 20
                 }
             }
         },
-        "model.ArticleStatus": {
-            "type": "integer",
-            "enum": [
-                0,
-                1,
-                2
+        "model.RenameTagRequest": {
+            "type": "object",
+            "required": [
+                "name"
             ],
-            "x-enum-comments": {
-                "Draft": "草稿",
-                "Pending": "待审核",
-                "Published": "已发布"
-            },
-            "x-enum-varnames": [
-                "Draft",
-                "Pending",
-                "Published"
-            ]
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "maxLength": 32
+                }
+            }
         },
-        "model.User": {
+        "model.ResetPasswordRequest": {
             "type": "object",
             "required": [
                 "password",
-                "username"
+                "token"
             ],
             "properties": {
-                "account": {
-                    "description": "账号，唯一",
+                "password": {
+                    "type": "string"
+                },
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.RevisionDiff": {
+            "type": "object",
+            "properties": {
+                "content": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/util.DiffSegment"
+                    }
+                },
+                "from": {
+                    "type": "integer"
+                },
+                "mode": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/util.DiffSegment"
+                    }
+                },
+                "to": {
+                    "type": "integer"
+                },
+                "unified": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.StepUpLoginRequest": {
+            "type": "object",
+            "required": [
+                "code",
+                "step_up_token"
+            ],
+            "properties": {
+                "code": {
+                    "description": "邮件中的验证码",
+                    "type": "string"
+                },
+                "step_up_token": {
+                    "description": "登录返回的临时令牌",
                     "type": "string"
+                }
+            }
+        },
+        "model.Tag": {
+            "type": "object",
+            "properties": {
+                "article_count": {
+                    "type": "integer"
                 },
                 "created_at": {
-                    "description": "创建时间",
                     "type": "string"
                 },
-                "email": {
-                    "description": "邮箱，唯一",
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.TokenResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "expires_at": {
                     "type": "string"
                 },
                 "id": {
                     "type": "integer"
                 },
-                "password": {
-                    "description": "密码",
+                "last_used_at": {
                     "type": "string"
                 },
-                "phone": {
-                    "description": "手机号",
+                "name": {
                     "type": "string"
                 },
-                "role": {
-                    "description": "角色",
+                "prefix": {
                     "type": "string"
                 },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "model.UpdateArticleStatusRequest": {
+            "type": "object",
+            "required": [
+                "status"
+            ],
+            "properties": {
+                "reason": {
+                    "type": "string",
+                    "maxLength": 255
+                },
                 "status": {
-                    "description": "状态 active/disabled",
+                    "type": "string",
+                    "enum": [
+                        "draft",
+                        "pending",
+                        "published",
+                        "rejected",
+                        "archived"
+                    ]
+                }
+            }
+        },
+        "model.UpdatePasswordRequest": {
+            "type": "object",
+            "required": [
+                "new_password",
+                "old_password"
+            ],
+            "properties": {
+                "new_password": {
                     "type": "string"
                 },
-                "updated_at": {
-                    "description": "更新时间",
+                "old_password": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.UpdateRoleRequest": {
+            "type": "object",
+            "required": [
+                "role"
+            ],
+            "properties": {
+                "role": {
+                    "type": "string",
+                    "maxLength": 20
+                }
+            }
+        },
+        "model.UpdateUserRequest": {
+            "type": "object",
+            "properties": {
+                "account": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "minLength": 1
+                },
+                "email": {
+                    "type": "string"
+                },
+                "phone": {
                     "type": "string"
                 },
                 "username": {
-                    "description": "用户名，唯一",
+                    "type": "string",
+                    "maxLength": 20,
+                    "minLength": 1
+                }
+            }
+        },
+        "model.UserIdentity": {
+            "type": "object",
+            "properties": {
+                "created_at": {
                     "type": "string"
                 },
-                "uuid": {
-                    "description": "用户唯一标识",
+                "email": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "last_login_at": {
+                    "type": "string"
+                },
+                "provider": {
                     "type": "string"
                 }
             }
@@ -748,9 +3366,21 @@ const docTemplate = `{
                 "deleted_at": {
                     "type": "string"
                 },
+                "disabled_reason": {
+                    "type": "string"
+                },
+                "disabled_until": {
+                    "type": "string"
+                },
                 "email": {
                     "type": "string"
                 },
+                "email_verified": {
+                    "type": "boolean"
+                },
+                "mfa_enabled": {
+                    "type": "boolean"
+                },
                 "phone": {
                     "type": "string"
                 },
@@ -770,6 +3400,65 @@ const docTemplate = `{
                     "type": "string"
                 }
             }
+        },
+        "model.VerifyEmailRequest": {
+            "type": "object",
+            "required": [
+                "token"
+            ],
+            "properties": {
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "search.Hit": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "score": {
+                    "type": "number"
+                },
+                "snippet": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "search.Result": {
+            "type": "object",
+            "properties": {
+                "hits": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/search.Hit"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "util.DiffSegment": {
+            "type": "object",
+            "properties": {
+                "op": {
+                    "type": "string"
+                },
+                "text": {
+                    "type": "string"
+                }
+            }
         }
     }
 }`
@@ -4,7 +4,6 @@ import (
 	"TestGin/api"
 	"TestGin/config"
 	"TestGin/middleware"
-	"TestGin/util"
 	"github.com/gin-gonic/gin"
 )
 
@@ -12,10 +11,26 @@ func main() {
 	// 创建Gin引擎
 	r := gin.Default()
 	config.InitConfig()
-	rdb := config.InitRedis()
-	middleware.InitJWTMiddleware(rdb)
+	// 认证状态存储：默认使用 Redis，session.store 为 memory 时不依赖 Redis
+	if config.Conf.Session.Store == "memory" {
+		middleware.InitJWTMiddleware(middleware.NewMemorySessionStore())
+	} else {
+		middleware.InitJWTMiddleware(middleware.NewRedisSessionStore(config.InitRedis()))
+	}
+	if err := middleware.InitJWTKeys(config.Conf.JWT); err != nil {
+		panic("JWT 密钥加载失败: " + err.Error())
+	}
+	middleware.InitRBAC(config.Conf.RBAC)
+	middleware.InitLoginGuard(config.Conf.Login)
+	middleware.InitCookie(config.Conf.Cookie)
 	config.InitDB()
-	util.InitWebsocket(r)
+	config.InitMailer()
+	config.InitPasswordPolicy()
+	config.InitOIDC()
+	config.InitLoginAlert()
+	config.StartAuditPurge()
+	config.InitSearch()
+	//util.InitWebsocket(r)
 
 	//fmt.Println("MySQL Host:", config.Conf.MySQL.Host)
 	// 注册路由
@@ -0,0 +1,143 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/golang-jwt/jwt/v5"
+	"strings"
+	"time"
+)
+
+// 一次性操作令牌：用于密码重置、邮箱验证等通过邮件链接完成的操作。
+// 令牌用当前密钥签名，jti 同时写入会话存储，使用时原子删除，保证只能使用一次。
+
+// 操作令牌类型
+const (
+	ActionPasswordReset = "password_reset"
+	ActionEmailVerify   = "email_verify"
+)
+
+// 操作令牌有效期
+const (
+	PasswordResetExpiration = 30 * time.Minute
+	EmailVerifyExpiration   = 24 * time.Hour
+)
+
+// ActionMailInterval 同一用户同类邮件的最小发送间隔
+const ActionMailInterval = time.Minute
+
+func actionTokenKey(jti string) string {
+	return fmt.Sprintf("action_token:%s", jti)
+}
+
+// CreateActionToken 签发一次性操作令牌
+func CreateActionToken(userID, action string, ttl time.Duration) (string, error) {
+	token, _, err := signActionToken(userID, action, "", ttl)
+	return token, err
+}
+
+// signActionToken 签名并登记操作令牌，返回令牌与 jti
+func signActionToken(userID, action, emailHash string, ttl time.Duration) (string, string, error) {
+	jti := generateUniqueID()
+	if jti == "" {
+		return "", "", errors.New("生成令牌ID失败")
+	}
+	claims := Claims{
+		UserID:    userID,
+		Type:      action,
+		EmailHash: emailHash,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        jti,
+			Issuer:    keySet.issuer,
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	}
+	token, err := keySet.Sign(claims)
+	if err != nil {
+		return "", "", err
+	}
+	if err = store.Set(actionTokenKey(jti), userID, ttl); err != nil {
+		return "", "", fmt.Errorf("缓存操作令牌失败: %v", err)
+	}
+	return token, jti, nil
+}
+
+// emailVerifyPendingKey 用户最后签发的邮箱验证令牌 jti
+func emailVerifyPendingKey(UUID string) string {
+	return fmt.Sprintf("email_verify_pending:%s", UUID)
+}
+
+// EmailHash 邮箱的 SHA-256（忽略大小写与首尾空白），写入验证令牌而不在链接中暴露邮箱
+func EmailHash(email string) string {
+	sum := sha256.Sum256([]byte(strings.ToLower(strings.TrimSpace(email))))
+	return hex.EncodeToString(sum[:])
+}
+
+// CreateEmailVerifyToken 签发邮箱验证令牌，令牌绑定发送时的邮箱；同一用户只有最后签发的令牌有效
+func CreateEmailVerifyToken(userID, email string) (string, error) {
+	token, jti, err := signActionToken(userID, ActionEmailVerify, EmailHash(email), EmailVerifyExpiration)
+	if err != nil {
+		return "", err
+	}
+	if err = store.Set(emailVerifyPendingKey(userID), jti, EmailVerifyExpiration); err != nil {
+		return "", fmt.Errorf("缓存操作令牌失败: %v", err)
+	}
+	return token, nil
+}
+
+// ConsumeEmailVerifyToken 校验并作废邮箱验证令牌。调用方还需比较 claims.EmailHash 与用户当前邮箱
+func ConsumeEmailVerifyToken(tokenString string) (*Claims, error) {
+	claims, err := ParseToken(tokenString)
+	if err != nil || claims.Type != ActionEmailVerify || claims.ID == "" || claims.EmailHash == "" {
+		return nil, errors.New("链接无效或已过期")
+	}
+	owner, err := store.GetDel(actionTokenKey(claims.ID))
+	if err != nil || owner != claims.UserID {
+		return nil, errors.New("链接已使用或已失效")
+	}
+	// 只有最新的链接能清除待验证记录；旧链接不能连带作废最新的链接
+	if ok, err := store.DelIfEqual(emailVerifyPendingKey(claims.UserID), claims.ID); err != nil || !ok {
+		return nil, errors.New("链接已失效，请使用最新的验证邮件")
+	}
+	return claims, nil
+}
+
+// InvalidateEmailVerify 作废用户尚未使用的邮箱验证令牌，修改邮箱时调用
+func InvalidateEmailVerify(UUID string) error {
+	return store.Del(emailVerifyPendingKey(UUID))
+}
+
+// ParseActionToken 校验操作令牌但不作废
+func ParseActionToken(tokenString, action string) (*Claims, error) {
+	claims, err := ParseToken(tokenString)
+	if err != nil || claims.Type != action || claims.ID == "" {
+		return nil, errors.New("链接无效或已过期")
+	}
+	if ok, err := store.Exists(actionTokenKey(claims.ID)); err != nil || !ok {
+		return nil, errors.New("链接已使用或已失效")
+	}
+	return claims, nil
+}
+
+// ConsumeActionToken 校验并作废操作令牌，返回令牌所属用户的 UUID
+func ConsumeActionToken(tokenString, action string) (string, error) {
+	claims, err := ParseToken(tokenString)
+	if err != nil || claims.Type != action || claims.ID == "" {
+		return "", errors.New("链接无效或已过期")
+	}
+	owner, err := store.GetDel(actionTokenKey(claims.ID))
+	if err != nil || owner != claims.UserID {
+		return "", errors.New("链接已使用或已失效")
+	}
+	return claims.UserID, nil
+}
+
+// AllowActionMail 限制同一用户同类邮件的发送频率
+func AllowActionMail(UUID, action string) bool {
+	key := fmt.Sprintf("action_mail:%s:%s", action, UUID)
+	ok, err := store.SetNX(key, "1", ActionMailInterval)
+	return err == nil && ok
+}
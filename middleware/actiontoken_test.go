@@ -0,0 +1,44 @@
+package middleware
+
+import "testing"
+
+func TestEmailVerifyTokenBoundToEmail(t *testing.T) {
+	setupAuth(t)
+	token, err := CreateEmailVerifyToken("u1", "A@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims, err := ConsumeEmailVerifyToken(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claims.EmailHash != EmailHash(" a@example.com ") || claims.EmailHash == EmailHash("victim@example.com") {
+		t.Fatal("令牌应绑定发送时的邮箱")
+	}
+	if _, err = ConsumeEmailVerifyToken(token); err == nil {
+		t.Fatal("令牌只能使用一次")
+	}
+}
+
+func TestEmailVerifyTokenInvalidated(t *testing.T) {
+	setupAuth(t)
+	old, _ := CreateEmailVerifyToken("u1", "a@example.com")
+	newer, err := CreateEmailVerifyToken("u1", "a@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = ConsumeEmailVerifyToken(old); err == nil {
+		t.Fatal("重新发送后旧链接应失效")
+	}
+	if _, err = ConsumeEmailVerifyToken(newer); err != nil {
+		t.Fatalf("打开旧链接不应作废最新的链接: %v", err)
+	}
+
+	latest, _ := CreateEmailVerifyToken("u1", "a@example.com")
+	if err := InvalidateEmailVerify("u1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ConsumeEmailVerifyToken(latest); err == nil {
+		t.Fatal("修改邮箱后待验证的链接应失效")
+	}
+}
@@ -0,0 +1,76 @@
+package middleware
+
+import (
+	"log"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 认证审计事件类型
+const (
+	AuthEventLoginSuccess   = "login_success"
+	AuthEventLoginFailure   = "login_failure"
+	AuthEventRefresh        = "refresh"
+	AuthEventRefreshReuse   = "refresh_token_reuse"
+	AuthEventLogout         = "logout"
+	AuthEventSessionRevoked = "session_revoked"
+	AuthEventPasswordChange = "password_change"
+	AuthEventPasswordReset  = "password_reset"
+	AuthEventRoleChange     = "role_change"
+	AuthEventUserDisabled   = "user_disabled"
+	AuthEventUserEnabled    = "user_enabled"
+	AuthEventLoginUnusual   = "login_unusual"
+
+	AuthEventImpersonationStart  = "impersonation_start"
+	AuthEventImpersonationAction = "impersonation_action"
+)
+
+// AuthEvent 认证审计事件
+type AuthEvent struct {
+	Type        string
+	UserID      string // 事件所属用户 UUID，登录时用户不存在则为空
+	ActorID     string // 操作者 UUID，管理员操作他人账号时与 UserID 不同
+	SessionID   string
+	IP          string
+	UserAgent   string
+	Reason      string
+	Fingerprint string // 以下三项在登录成功时记录，用于识别新设备与新网络
+	Network     string
+	Country     string
+	Time        time.Time
+}
+
+// AuthEventRecorder 持久化审计事件，由 api 包注册（写入 auth_events 表）
+type AuthEventRecorder func(event *AuthEvent)
+
+var authEventRecorder AuthEventRecorder
+
+// SetAuthEventRecorder 注册审计事件的持久化实现
+func SetAuthEventRecorder(r AuthEventRecorder) {
+	authEventRecorder = r
+}
+
+// RecordAuthEvent 记录审计事件，未注册持久化实现时只打印日志
+func RecordAuthEvent(event *AuthEvent) {
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+	log.Printf("📝 认证事件 %s: 用户 %s IP %s %s", event.Type, event.UserID, event.IP, event.Reason)
+	if authEventRecorder != nil {
+		authEventRecorder(event)
+	}
+}
+
+// RecordAuthEventFromContext 记录当前请求触发的审计事件，IP、User-Agent、操作者与会话取自请求上下文
+func RecordAuthEventFromContext(c *gin.Context, eventType, UUID, reason string) {
+	RecordAuthEvent(&AuthEvent{
+		Type:      eventType,
+		UserID:    UUID,
+		ActorID:   c.GetString("actorID"),
+		SessionID: c.GetString("sessionID"),
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+		Reason:    reason,
+	})
+}
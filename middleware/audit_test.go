@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAuthEventsRecorded(t *testing.T) {
+	s := setupAuth(t)
+	var events []*AuthEvent
+	SetAuthEventRecorder(func(e *AuthEvent) { events = append(events, e) })
+	defer SetAuthEventRecorder(nil)
+
+	pair, _ := Login("u1", "alice", "user", testDevice)
+	if _, err := RefreshAccessToken(pair.RefreshToken); err != nil {
+		t.Fatal(err)
+	}
+	old, _ := ParseToken(pair.RefreshToken)
+	s.mu.Lock()
+	s.refresh[old.ID].value.RotatedAt = time.Now().Add(-2 * RefreshReuseGrace).Unix()
+	s.mu.Unlock()
+	RefreshAccessToken(pair.RefreshToken)
+
+	if len(events) != 2 {
+		t.Fatalf("应记录登录与重复使用两条事件，实际 %d", len(events))
+	}
+	if e := events[0]; e.Type != AuthEventLoginSuccess || e.UserID != "u1" || e.IP != testDevice.IP || e.SessionID != old.SessionID {
+		t.Fatalf("登录事件错误: %+v", e)
+	}
+	// 重复使用时会话已被撤销，IP 仍取自会话
+	if e := events[1]; e.Type != AuthEventRefreshReuse || e.IP != testDevice.IP || e.Reason == "" {
+		t.Fatalf("重复使用事件错误: %+v", e)
+	}
+}
+
+func TestStepUpCode(t *testing.T) {
+	setupAuth(t)
+	token, code, err := CreateStepUpToken("u1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims, err := ParseStepUpToken(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if VerifyStepUpCode(claims, "not-it") {
+		t.Fatal("错误的验证码不应通过")
+	}
+	if !VerifyStepUpCode(claims, code) {
+		t.Fatal("正确的验证码应通过")
+	}
+	if _, err = ParseStepUpToken(token); err == nil {
+		t.Fatal("验证后临时令牌应失效")
+	}
+	if VerifyStepUpCode(claims, code) {
+		t.Fatal("验证码只能使用一次")
+	}
+}
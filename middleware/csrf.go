@@ -0,0 +1,169 @@
+package middleware
+
+import (
+	"TestGin/config"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// RefreshCookieName 刷新令牌 Cookie，HttpOnly，脚本不可读
+	RefreshCookieName = "refresh_token"
+	// CSRFCookieName 双重提交的 CSRF 令牌 Cookie（Path=/），脚本可读，请求时放到 CSRFHeaderName 头中
+	CSRFCookieName = "csrf_token"
+	CSRFHeaderName = "X-CSRF-Token"
+)
+
+var cookieConf = config.CookieConfig{
+	Path:     "/api/user",
+	SameSite: "strict",
+}
+
+// InitCookie 加载刷新令牌 Cookie 配置，未配置的项使用默认值
+func InitCookie(conf config.CookieConfig) {
+	if conf.Path != "" {
+		cookieConf.Path = conf.Path
+	}
+	if conf.SameSite != "" {
+		cookieConf.SameSite = conf.SameSite
+	}
+	cookieConf.Domain = conf.Domain
+	cookieConf.Insecure = conf.Insecure
+	cookieConf.AllowedOrigins = conf.AllowedOrigins
+}
+
+func sameSiteMode() http.SameSite {
+	switch strings.ToLower(cookieConf.SameSite) {
+	case "lax":
+		return http.SameSiteLaxMode
+	case "none":
+		return http.SameSiteNoneMode
+	default:
+		return http.SameSiteStrictMode
+	}
+}
+
+// setCookie 按配置写入 Cookie；SameSite=None 时浏览器要求 Secure，忽略 insecure 配置
+func setCookie(c *gin.Context, name, value string, maxAge int, httpOnly bool) {
+	writeCookie(c, name, value, cookieConf.Path, maxAge, httpOnly, sameSiteMode())
+}
+
+func writeCookie(c *gin.Context, name, value, path string, maxAge int, httpOnly bool, mode http.SameSite) {
+	c.SetSameSite(mode)
+	secure := !cookieConf.Insecure || mode == http.SameSiteNoneMode
+	c.SetCookie(name, value, maxAge, path, cookieConf.Domain, secure, httpOnly)
+}
+
+// OIDCBindingCookieName 第三方登录发起时写入浏览器的随机值，回调时必须带回，防止登录 CSRF 与关联 CSRF
+const OIDCBindingCookieName = "oidc_binding"
+
+// oidcCookiePath 只在第三方登录回调路径下发送
+const oidcCookiePath = "/api/user/oidc"
+
+// SetOIDCBindingCookie 生成随机值写入 HttpOnly Cookie，返回其哈希，由调用方与 state 一起保存。
+// 身份提供方回调是跨站的顶层跳转，SameSite 必须为 Lax
+func SetOIDCBindingCookie(c *gin.Context, maxAge int) (string, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	value := base64.RawURLEncoding.EncodeToString(buf)
+	writeCookie(c, OIDCBindingCookieName, value, oidcCookiePath, maxAge, true, http.SameSiteLaxMode)
+	return hashBinding(value), nil
+}
+
+func hashBinding(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:])
+}
+
+// CheckOIDCBindingCookie 校验回调请求带回的 Cookie 与发起时保存的哈希一致，校验后删除 Cookie
+func CheckOIDCBindingCookie(c *gin.Context, binding string) bool {
+	value, err := c.Cookie(OIDCBindingCookieName)
+	writeCookie(c, OIDCBindingCookieName, "", oidcCookiePath, -1, true, http.SameSiteLaxMode)
+	if err != nil || value == "" || binding == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(hashBinding(value)), []byte(binding)) == 1
+}
+
+// SetRefreshCookie 写入刷新令牌 Cookie，并下发新的 CSRF 令牌，返回 CSRF 令牌
+func SetRefreshCookie(c *gin.Context, refreshToken string) (string, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	csrf := base64.RawURLEncoding.EncodeToString(buf)
+	maxAge := int(RefreshTokenExpiration.Seconds())
+	setCookie(c, RefreshCookieName, refreshToken, maxAge, true)
+	setCSRFCookie(c, csrf, maxAge)
+	c.Header(CSRFHeaderName, csrf)
+	return csrf, nil
+}
+
+// setCSRFCookie CSRF Cookie 写在根路径下，页面脚本才能通过 document.cookie 读取；刷新令牌 Cookie 仍只发往 cookie.path。
+// 同时删除旧版本写在 cookie.path 下的同名 Cookie，否则请求时它排在前面，校验会失败
+func setCSRFCookie(c *gin.Context, value string, maxAge int) {
+	if cookieConf.Path != "/" {
+		setCookie(c, CSRFCookieName, "", -1, false)
+	}
+	writeCookie(c, CSRFCookieName, value, "/", maxAge, false, sameSiteMode())
+}
+
+// ClearRefreshCookie 删除刷新令牌与 CSRF Cookie
+func ClearRefreshCookie(c *gin.Context) {
+	setCookie(c, RefreshCookieName, "", -1, true)
+	setCSRFCookie(c, "", -1)
+}
+
+// CSRFProtect 保护依赖 Cookie 的接口：
+// 带 Origin（或 Referer）时必须是本站或 cookie.allowedorigins 中的来源；
+// 同时要求 X-CSRF-Token 头与 csrf_token Cookie 一致（双重提交），跨站页面读不到 Cookie，无法伪造
+func CSRFProtect() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := checkOrigin(c.Request); err != nil {
+			ErrorWithCode(c, http.StatusForbidden, CodeCSRFRejected, err)
+			return
+		}
+		cookie, err := c.Cookie(CSRFCookieName)
+		header := c.GetHeader(CSRFHeaderName)
+		if err != nil || cookie == "" || subtle.ConstantTimeCompare([]byte(cookie), []byte(header)) != 1 {
+			ErrorWithCode(c, http.StatusForbidden, CodeCSRFRejected, errors.New("CSRF 令牌无效"))
+			return
+		}
+		c.Next()
+	}
+}
+
+// checkOrigin 校验请求来源，Origin 与 Referer 都没有时（非浏览器客户端）交给 CSRF 令牌校验
+func checkOrigin(r *http.Request) error {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		if referer := r.Header.Get("Referer"); referer != "" {
+			if u, err := url.Parse(referer); err == nil {
+				origin = u.Scheme + "://" + u.Host
+			}
+		}
+	}
+	if origin == "" {
+		return nil
+	}
+	for _, allowed := range cookieConf.AllowedOrigins {
+		if strings.EqualFold(strings.TrimRight(allowed, "/"), origin) {
+			return nil
+		}
+	}
+	if u, err := url.Parse(origin); err == nil && u.Host != "" && strings.EqualFold(u.Host, r.Host) {
+		return nil
+	}
+	return errors.New("请求来源不被允许")
+}
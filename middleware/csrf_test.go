@@ -0,0 +1,138 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// newCSRFRouter /login 下发 Cookie，/refresh 受 CSRFProtect 保护
+func newCSRFRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/api/user/login", func(c *gin.Context) {
+		if _, err := SetRefreshCookie(c, "rt"); err != nil {
+			c.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+		c.Status(http.StatusOK)
+	})
+	r.POST("/api/user/refresh", CSRFProtect(), func(c *gin.Context) { c.Status(http.StatusNoContent) })
+	return r
+}
+
+func csrfLogin(t *testing.T, r *gin.Engine) (csrf string, cookies []*http.Cookie) {
+	t.Helper()
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/user/login", nil))
+	for _, ck := range w.Result().Cookies() {
+		// MaxAge<0 的是删除旧路径下同名 Cookie 的指令，浏览器不会带上
+		if ck.MaxAge < 0 {
+			continue
+		}
+		cookies = append(cookies, ck)
+		if ck.Name == RefreshCookieName && (!ck.HttpOnly || ck.SameSite != http.SameSiteStrictMode || ck.Path != "/api/user") {
+			t.Fatalf("刷新令牌 Cookie 属性错误: %+v", ck)
+		}
+		if ck.Name == CSRFCookieName {
+			if ck.HttpOnly || ck.Path != "/" {
+				t.Fatalf("CSRF Cookie 应在根路径下且脚本可读: %+v", ck)
+			}
+			csrf = ck.Value
+		}
+	}
+	if csrf == "" || w.Header().Get(CSRFHeaderName) != csrf {
+		t.Fatal("应下发 CSRF 令牌")
+	}
+	return csrf, cookies
+}
+
+func TestCSRFProtect(t *testing.T) {
+	r := newCSRFRouter()
+	csrf, cookies := csrfLogin(t, r)
+
+	refresh := func(header, origin string) int {
+		req := httptest.NewRequest(http.MethodPost, "/api/user/refresh", nil)
+		for _, ck := range cookies {
+			req.AddCookie(ck)
+		}
+		if header != "" {
+			req.Header.Set(CSRFHeaderName, header)
+		}
+		if origin != "" {
+			req.Header.Set("Origin", origin)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	if code := refresh("", ""); code != http.StatusForbidden {
+		t.Fatalf("缺少 CSRF 头应返回 403，实际 %d", code)
+	}
+	if code := refresh("forged", ""); code != http.StatusForbidden {
+		t.Fatalf("CSRF 令牌不一致应返回 403，实际 %d", code)
+	}
+	if code := refresh(csrf, "https://evil.example.com"); code != http.StatusForbidden {
+		t.Fatalf("跨站来源应返回 403，实际 %d", code)
+	}
+	if code := refresh(csrf, "http://example.com"); code != http.StatusNoContent {
+		t.Fatalf("同站请求应通过，实际 %d", code)
+	}
+	if code := refresh(csrf, ""); code != http.StatusNoContent {
+		t.Fatalf("非浏览器客户端带 CSRF 令牌应通过，实际 %d", code)
+	}
+
+	cookieConf.AllowedOrigins = []string{"https://app.example.com"}
+	defer func() { cookieConf.AllowedOrigins = nil }()
+	if code := refresh(csrf, "https://app.example.com"); code != http.StatusNoContent {
+		t.Fatalf("允许的跨站来源应通过，实际 %d", code)
+	}
+}
+
+func TestOIDCBindingCookie(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var binding string
+	r := gin.New()
+	r.GET("/begin", func(c *gin.Context) {
+		binding, _ = SetOIDCBindingCookie(c, 600)
+	})
+	r.GET("/api/user/oidc/test/callback", func(c *gin.Context) {
+		if !CheckOIDCBindingCookie(c, binding) {
+			c.Status(http.StatusBadRequest)
+			return
+		}
+		c.Status(http.StatusOK)
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/begin", nil))
+	var cookie *http.Cookie
+	for _, ck := range w.Result().Cookies() {
+		if ck.Name == OIDCBindingCookieName {
+			cookie = ck
+		}
+	}
+	if cookie == nil || !cookie.HttpOnly || cookie.SameSite != http.SameSiteLaxMode || cookie.Value == binding {
+		t.Fatalf("绑定 Cookie 属性错误: %+v", cookie)
+	}
+
+	// 他人浏览器没有 Cookie，或 Cookie 不匹配
+	for _, ck := range []*http.Cookie{nil, {Name: OIDCBindingCookieName, Value: "attacker"}, cookie} {
+		req := httptest.NewRequest(http.MethodGet, "/api/user/oidc/test/callback", nil)
+		if ck != nil {
+			req.AddCookie(ck)
+		}
+		w = httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		want := http.StatusBadRequest
+		if ck == cookie {
+			want = http.StatusOK
+		}
+		if w.Code != want {
+			t.Fatalf("cookie %v: 状态码 %d，期望 %d", ck, w.Code, want)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+func denylistKey(jti string) string {
+	return fmt.Sprintf("denylist:%s", jti)
+}
+
+// DenyToken 将令牌加入黑名单，TTL 等于令牌剩余有效期
+func DenyToken(claims *Claims) error {
+	if claims.ID == "" {
+		return errors.New("令牌缺少 jti")
+	}
+	ttl := time.Until(claims.ExpiresAt.Time)
+	if ttl <= 0 {
+		return nil
+	}
+	return store.Set(denylistKey(claims.ID), claims.UserID, ttl)
+}
+
+// IsTokenDenied 检查令牌是否在黑名单中，查询失败时按已拉黑处理
+func IsTokenDenied(jti string) bool {
+	if jti == "" {
+		return false
+	}
+	exists, err := store.Exists(denylistKey(jti))
+	return err != nil || exists
+}
+
+// Logout 注销：拉黑当前访问令牌并撤销其所属会话
+func Logout(claims *Claims) error {
+	if err := DenyToken(claims); err != nil {
+		return err
+	}
+	return deleteSessions(claims.UserID, claims.SessionID)
+}
@@ -11,6 +11,14 @@ import (
 	"time"
 )
 
+// skipCacheKey 处理函数标记本次响应不写入缓存
+const skipCacheKey = "cache:skip"
+
+// SkipCache 本次响应不写入缓存，用于只对部分用户可见的内容
+func SkipCache(c *gin.Context) {
+	c.Set(skipCacheKey, true)
+}
+
 type CacheOptions struct {
 	RedisClient *redisChea.Client
 	TTL         time.Duration
@@ -25,6 +33,11 @@ func RedisCacheMiddleware(opts CacheOptions, handler gin.HandlerFunc) gin.Handle
 	}
 
 	return func(c *gin.Context) {
+		// 未启用 Redis 时不缓存
+		if opts.RedisClient == nil {
+			handler(c)
+			return
+		}
 		ctx := context.Background()
 		cacheKey := opts.KeyFunc(c)
 
@@ -40,8 +53,8 @@ func RedisCacheMiddleware(opts CacheOptions, handler gin.HandlerFunc) gin.Handle
 			writer := &bodyWriter{ResponseWriter: c.Writer, body: bytes.NewBuffer(nil)}
 			c.Writer = writer
 			handler(c)
-			// 如果状态码是 200，写入缓存
-			if c.Writer.Status() == 200 {
+			// 如果状态码是 200 且处理函数没有跳过缓存，写入缓存
+			if c.Writer.Status() == 200 && !c.GetBool(skipCacheKey) {
 				go func() {
 					err := opts.RedisClient.Set(ctx, cacheKey, writer.body.String(), opts.TTL).Err()
 					if err == nil {
@@ -0,0 +1,73 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// ImpersonationExpiration 模拟登录令牌有效期，不签发刷新令牌，到期后需重新申请
+const ImpersonationExpiration = 10 * time.Minute
+
+// ImpersonatedByHeader 使用模拟登录令牌时，响应头中带上真实操作者的 UUID
+const ImpersonatedByHeader = "X-Impersonated-By"
+
+// Actor 真实操作者，对应令牌中的 act 声明 (RFC 8693)
+type Actor struct {
+	Subject  string `json:"sub"`
+	Username string `json:"name,omitempty"`
+}
+
+// CreateImpersonationToken 管理员以目标用户身份签发短期访问令牌。
+// 令牌挂在管理员当前会话上：管理员注销或会话被撤销时，模拟登录随之失效
+func CreateImpersonationToken(UUID, username, role string, admin *Claims) (string, error) {
+	if admin.Actor != nil {
+		return "", errors.New("模拟登录时不能再次模拟")
+	}
+	claims := Claims{
+		UserID:    UUID,
+		Username:  username,
+		Role:      role,
+		Type:      "access",
+		SessionID: admin.SessionID,
+		Actor:     &Actor{Subject: admin.UserID, Username: admin.Username},
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        generateUniqueID(),
+			Issuer:    keySet.issuer,
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ImpersonationExpiration)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	}
+	return keySet.Sign(claims)
+}
+
+// DenyImpersonation 禁止模拟登录令牌访问，用于修改资料等账号持有人才能做的操作
+func DenyImpersonation() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetBool("impersonating") {
+			Error(c, http.StatusForbidden, errors.New("模拟登录时不能执行该操作"))
+			return
+		}
+		c.Next()
+	}
+}
+
+// auditImpersonation 记录模拟登录期间的写操作
+func auditImpersonation(c *gin.Context) {
+	switch c.Request.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return
+	}
+	RecordAuthEvent(&AuthEvent{
+		Type:      AuthEventImpersonationAction,
+		UserID:    c.GetString("userID"),
+		ActorID:   c.GetString("actorID"),
+		SessionID: c.GetString("sessionID"),
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+		Reason:    c.Request.Method + " " + c.Request.URL.Path + " -> " + http.StatusText(c.Writer.Status()),
+	})
+}
@@ -0,0 +1,78 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestImpersonationToken(t *testing.T) {
+	setupAuth(t)
+	var events []*AuthEvent
+	SetAuthEventRecorder(func(e *AuthEvent) { events = append(events, e) })
+	defer SetAuthEventRecorder(nil)
+
+	admin, _ := Login("admin1", "root", "admin", testDevice)
+	adminClaims, _ := ParseToken(admin.AccessToken)
+	token, err := CreateImpersonationToken("u1", "alice", "user", adminClaims)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := gin.New()
+	api := r.Group("/api", JWTAuthMiddleware())
+	api.GET("/me", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"user_id": c.GetString("userID"), "actor_id": c.GetString("actorID")})
+	})
+	api.POST("/profile", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+	api.POST("/password", RequireSession(), func(c *gin.Context) { c.Status(http.StatusNoContent) })
+
+	w := doGet(r, "/api/me", token)
+	if w.Code != http.StatusOK || w.Body.String() != `{"actor_id":"admin1","user_id":"u1"}` {
+		t.Fatalf("上下文错误: %d %s", w.Code, w.Body.String())
+	}
+	if w.Header().Get(ImpersonatedByHeader) != "admin1" {
+		t.Fatal("响应应带有真实操作者")
+	}
+
+	post := func(path string) int {
+		req := httptest.NewRequest(http.MethodPost, path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+	if code := post("/api/password"); code != http.StatusForbidden {
+		t.Fatalf("模拟登录不能执行敏感操作，实际 %d", code)
+	}
+	events = nil
+	if code := post("/api/profile"); code != http.StatusNoContent {
+		t.Fatalf("普通写操作应允许，实际 %d", code)
+	}
+	if len(events) != 1 || events[0].Type != AuthEventImpersonationAction || events[0].ActorID != "admin1" || events[0].UserID != "u1" {
+		t.Fatalf("写操作应记录审计日志: %+v", events)
+	}
+
+	if _, err = CreateImpersonationToken("u2", "bob", "user", mustParse(t, token)); err == nil {
+		t.Fatal("模拟登录时不能再次模拟")
+	}
+
+	// 管理员注销后模拟登录令牌随之失效
+	if err = Logout(adminClaims); err != nil {
+		t.Fatal(err)
+	}
+	if w := doGet(r, "/api/me", token); w.Code != http.StatusUnauthorized {
+		t.Fatalf("管理员会话失效后应返回 401，实际 %d", w.Code)
+	}
+}
+
+func mustParse(t *testing.T, token string) *Claims {
+	t.Helper()
+	claims, err := ParseToken(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return claims
+}
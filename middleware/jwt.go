@@ -8,22 +8,15 @@ import (
 	"fmt"
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
-	"github.com/redis/go-redis/v9"
 	"log"
 	"net/http"
 	"strings"
-	"sync"
 	"time"
 )
 
-// CreateToken  创建token
-
-const SIGNINGKEY = "your-very-long-and-complex-secret-key-at-least-32-characters-long"
-
 var (
-	initRedis *redis.Client
-	ctx       = context.Background()
-	once      sync.Once
+	store SessionStore
+	ctx   = context.Background()
 )
 
 const (
@@ -39,15 +32,25 @@ type TokenPair struct {
 
 // Claims 自定义Claims结构
 type Claims struct {
-	UserID   string `json:"sub"`
-	Username string `json:"name,omitempty"`
-	Type     string `json:"type"`
+	UserID    string `json:"sub"`
+	Username  string `json:"name,omitempty"`
+	Role      string `json:"role,omitempty"` // 用户角色，用于权限校验
+	Type      string `json:"type"`
+	SessionID string `json:"sid"`              // 所属登录会话（即刷新令牌家族）
+	ParentID  string `json:"parent,omitempty"` // 刷新令牌的父令牌 jti
+	Actor     *Actor `json:"act,omitempty"`    // 模拟登录时的真实操作者
+	EmailHash string `json:"eh,omitempty"`     // 邮箱验证令牌绑定的邮箱 (EmailHash)
 	jwt.RegisteredClaims
 }
 
-// InitJWTMiddleware 获取Redis客户端
-func InitJWTMiddleware(redis *redis.Client) {
-	initRedis = redis
+// InitJWTMiddleware 设置认证状态存储，可使用 NewRedisSessionStore 或 NewMemorySessionStore
+func InitJWTMiddleware(s SessionStore) {
+	store = s
+}
+
+// GetSessionStore 获取认证状态存储
+func GetSessionStore() SessionStore {
+	return store
 }
 
 // 生成唯一ID
@@ -61,67 +64,88 @@ func generateUniqueID() string {
 }
 
 // CreateAccessToken 创建访问令牌 (短期 - 15分钟)
-// CreateAccessToken 创建访问令牌 (短期 - 15分钟)
-func CreateAccessToken(userID, username string) (string, error) {
+func CreateAccessToken(userID, username, role, sessionID string) (string, error) {
 	claims := Claims{
-		UserID:   userID,
-		Username: username,
-		Type:     "access",
+		UserID:    userID,
+		Username:  username,
+		Role:      role,
+		Type:      "access",
+		SessionID: sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        generateUniqueID(),
+			Issuer:    keySet.issuer,
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(AccessTokenExpiration)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(SIGNINGKEY))
+	return keySet.Sign(claims)
 }
 
 // CreateRefreshToken 创建刷新令牌 (长期 - 7天)
-func CreateRefreshToken(userID string) (string, error) {
+func CreateRefreshToken(userID, sessionID, jti, parentID string) (string, error) {
 	claims := Claims{
-		UserID: userID,
-		Type:   "refresh",
+		UserID:    userID,
+		Type:      "refresh",
+		SessionID: sessionID,
+		ParentID:  parentID,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        jti,
+			Issuer:    keySet.issuer,
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(RefreshTokenExpiration)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(SIGNINGKEY))
+	return keySet.Sign(claims)
 }
 
-func Login(UUID, username string) (*TokenPair, error) {
-	accessToken, err := CreateAccessToken(UUID, username)
+// Login 为一次登录创建独立会话并签发令牌对
+func Login(UUID, username, role string, device DeviceInfo) (*TokenPair, error) {
+	session, err := createSession(UUID, username, role, device)
 	if err != nil {
 		return nil, err
 	}
-
-	refreshToken, err := CreateRefreshToken(UUID)
+	// 新的刷新令牌家族
+	jti := generateUniqueID()
+	if err = storeRefreshRecord(session, jti, ""); err != nil {
+		return nil, err
+	}
+	tokenPair, err := issueTokens(session, "")
 	if err != nil {
 		return nil, err
 	}
-	userKey := fmt.Sprintf("jwt:%s", UUID)
-	err = initRedis.HMSet(ctx, userKey, map[string]interface{}{
-		"userUUID":    UUID,
-		"username":    username,
-		"login_time":  time.Now().Unix(),
-		"last_active": time.Now().Unix(),
-	}).Err()
+
+	log.Printf("✅ 用户 %s 登录成功，会话 %s 已创建\n", username, session.ID)
+	RecordAuthEvent(&AuthEvent{
+		Type:        AuthEventLoginSuccess,
+		UserID:      UUID,
+		SessionID:   session.ID,
+		IP:          device.IP,
+		UserAgent:   device.UserAgent,
+		Fingerprint: device.Fingerprint(),
+		Network:     device.Network(),
+		Country:     device.Country,
+	})
+	return tokenPair, nil
+}
+
+// issueTokens 为会话签发令牌对，刷新令牌使用会话当前的 jti
+func issueTokens(session *Session, parentID string) (*TokenPair, error) {
+	accessToken, err := CreateAccessToken(session.UserID, session.Username, session.Role, session.ID)
 	if err != nil {
-		log.Printf("缓存用户信息失败: %v", err)
+		return nil, err
 	}
-	initRedis.Expire(ctx, userKey, AccessTokenExpiration)
-	// 缓存Refresh Token (7天过期)
-	refreshKey := fmt.Sprintf("refresh:%s", UUID)
-	err = initRedis.Set(ctx, refreshKey, refreshToken, RefreshTokenExpiration).Err()
+
+	refreshToken, err := CreateRefreshToken(session.UserID, session.ID, session.RefreshJTI, parentID)
 	if err != nil {
-		log.Printf("缓存刷新令牌失败: %v", err)
+		return nil, err
+	}
+	// 会话随刷新续期 (7天过期)
+	if err = store.ExtendSession(session, RefreshTokenExpiration); err != nil {
+		log.Printf("会话续期失败: %v", err)
 	}
 
-	log.Printf("✅ 用户 %s 登录成功，信息已缓存\n", username)
-	//添加到响应头中
 	return &TokenPair{
 		AccessToken:  accessToken,
 		RefreshToken: refreshToken,
@@ -131,7 +155,18 @@ func Login(UUID, username string) (*TokenPair, error) {
 // 需要排除的路由
 var excludePaths = []string{
 	"/api/user/login",
+	"/api/user/login/mfa",
+	"/api/user/login/step-up",
 	"/api/user/refresh",
+	"/api/user/add",
+	"/api/user/password/forgot",
+	"/api/user/password/reset",
+	"/api/user/email/verify",
+}
+
+// excludePrefixes 带路径参数的免认证路由
+var excludePrefixes = []string{
+	"/api/user/oidc/",
 }
 
 func isExcludedPath(path string) bool {
@@ -140,6 +175,11 @@ func isExcludedPath(path string) bool {
 			return true
 		}
 	}
+	for _, p := range excludePrefixes {
+		if strings.HasPrefix(path, p) {
+			return true
+		}
+	}
 	return false
 }
 
@@ -165,6 +205,14 @@ func JWTAuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
+		// 个人访问令牌
+		if IsPAT(parts[1]) {
+			if authenticatePAT(c, parts[1]) {
+				c.Next()
+			}
+			return
+		}
+
 		// 解析令牌
 		claims, err := ParseToken(parts[1])
 		if err != nil {
@@ -180,22 +228,50 @@ func JWTAuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		// 检查用户是否仍然存在于Redis中
-		userKey := fmt.Sprintf("jwt:%s", claims.UserID)
-		exists, err := initRedis.Exists(ctx, userKey).Result()
-		if err != nil || exists == 0 {
+		// 检查令牌是否已被注销
+		if IsTokenDenied(claims.ID) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "令牌已注销"})
+			c.Abort()
+			return
+		}
+
+		// 检查账号是否被禁用
+		if IsUserDisabled(claims.UserID) {
+			c.JSON(http.StatusForbidden, gin.H{"error": ErrAccountDisabled.Error()})
+			c.Abort()
+			return
+		}
+
+		// 检查令牌所属会话是否仍然有效，模拟登录令牌属于管理员的会话
+		owner := claims.UserID
+		if claims.Actor != nil {
+			owner = claims.Actor.Subject
+		}
+		session, err := GetSession(claims.SessionID)
+		if err != nil || session.UserID != owner {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "用户会话已过期"})
 			c.Abort()
 			return
 		}
 
-		// 更新用户最后活跃时间
-		initRedis.HSet(ctx, userKey, "last_active", time.Now().Unix())
-		initRedis.Expire(ctx, userKey, AccessTokenExpiration)
+		// 更新会话最后活跃时间
+		touchSession(session.ID)
 
 		// 将用户信息存储到上下文中
 		c.Set("userID", claims.UserID)
 		c.Set("username", claims.Username)
+		c.Set("role", claims.Role)
+		c.Set("sessionID", claims.SessionID)
+		c.Set("claims", claims)
+		// userID 为生效的用户，actorID 为真实操作者，只有模拟登录时两者不同
+		c.Set("actorID", owner)
+		if claims.Actor != nil {
+			c.Set("impersonating", true)
+			c.Header(ImpersonatedByHeader, claims.Actor.Subject)
+			c.Next()
+			auditImpersonation(c)
+			return
+		}
 
 		c.Next()
 	}
@@ -212,39 +288,29 @@ func RefreshAccessToken(refreshTokenString string) (*TokenPair, error) {
 	if claims.Type != "refresh" {
 		return nil, errors.New("刷新令牌无效")
 	}
-	//2.从缓存中获取对应的刷新令牌
-	refreshKey := fmt.Sprintf("refresh:%s", claims.UserID)
-	storedRefreshToken, err := initRedis.Get(ctx, refreshKey).Result()
-	if err != nil {
-		log.Printf("无法获取刷新令牌: %v", err)
-		return nil, errors.New("刷新令牌已过期")
+	if claims.ID == "" {
+		return nil, errors.New("刷新令牌无效")
 	}
-	//3.对比刷新令牌
-	if storedRefreshToken != refreshTokenString {
-		log.Printf("刷新令牌不匹配")
-		// 立即撤销所有该用户的令牌
-		err = RevokeAllUserTokens(claims.UserID)
-		if err != nil {
-			log.Printf("撤销用户令牌失败: %v", err)
-		}
-		return nil, errors.New("检测到安全威胁，所有令牌已被撤销")
+	//2.获取令牌所属会话（令牌家族）
+	session, err := GetSession(claims.SessionID)
+	if err != nil || session.UserID != claims.UserID {
+		return nil, errors.New("会话已失效")
 	}
-	//获取到用户名
-	tokenPair, err1 := Login(claims.UserID, claims.Username)
+	if IsUserDisabled(claims.UserID) {
+		return nil, ErrAccountDisabled
+	}
+	//3.按令牌家族轮换，重复使用只撤销该家族
+	jti, parentID, err := rotateRefreshToken(session, claims.ID)
 	if err != nil {
-		return nil, err1
+		return nil, err
 	}
-	return tokenPair, nil
+	session.RefreshJTI = jti
+	return issueTokens(session, parentID)
 }
 
-// ParseToken 解析JWT令牌
+// ParseToken 解析JWT令牌，按头部 kid 选择验签密钥
 func ParseToken(tokenString string) (*Claims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("无效的签名方法%v\n", token.Header["alg"])
-		}
-		return []byte(SIGNINGKEY), nil
-	})
+	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, keySet.Keyfunc, keySet.parserOptions()...)
 	if err != nil {
 		return nil, err
 	}
@@ -276,25 +342,21 @@ func ValidateToken(tokenString string) (*Claims, error) {
 	if time.Now().Unix() > claims.ExpiresAt.Unix() {
 		return nil, errors.New("令牌已过期")
 	}
+	if IsTokenDenied(claims.ID) {
+		return nil, errors.New("令牌已注销")
+	}
 
 	return claims, nil
 }
 
-// RevokeAllUserTokens 撤销用户所有令牌的辅助函数
+// RevokeAllUserTokens 撤销用户所有会话及令牌
 func RevokeAllUserTokens(UUID string) error {
-	userKey := fmt.Sprintf("jwt:%s", UUID)
-	refreshKey := fmt.Sprintf("refresh:%s", UUID)
-
-	// 删除用户会话信息
-	err := initRedis.Del(ctx, userKey).Err()
+	ids, err := store.ListSessionIDs(UUID)
 	if err != nil {
-		return fmt.Errorf("删除用户会话失败: %v", err)
+		return fmt.Errorf("获取用户会话失败: %v", err)
 	}
-
-	// 删除刷新令牌
-	err = initRedis.Del(ctx, refreshKey).Err()
-	if err != nil {
-		return fmt.Errorf("删除刷新令牌失败: %v", err)
+	if err = deleteSessions(UUID, ids...); err != nil {
+		return err
 	}
 
 	log.Printf("🔒 用户 %s 的所有令牌已被撤销", UUID)
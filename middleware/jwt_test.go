@@ -0,0 +1,345 @@
+package middleware
+
+import (
+	"TestGin/config"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// setupAuth 使用内存存储与测试密钥初始化认证
+func setupAuth(t *testing.T) *MemorySessionStore {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	s := NewMemorySessionStore()
+	InitJWTMiddleware(s)
+	t.Setenv("TEST_JWT_SECRET", "test-secret-key-at-least-32-characters")
+	err := InitJWTKeys(config.JWTConfig{
+		Issuer:    "testgin",
+		ActiveKID: "test",
+		Keys:      []config.JWTKeyConfig{{KID: "test", Alg: "HS256", SecretEnv: "TEST_JWT_SECRET"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+var testDevice = DeviceInfo{Name: "laptop", UserAgent: "go-test", IP: "127.0.0.1"}
+
+// newAuthRouter 受保护路由，返回上下文中的用户信息
+func newAuthRouter() *gin.Engine {
+	r := gin.New()
+	api := r.Group("/api", JWTAuthMiddleware())
+	api.GET("/me", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{
+			"user_id":    c.GetString("userID"),
+			"role":       c.GetString("role"),
+			"session_id": c.GetString("sessionID"),
+		})
+	})
+	return r
+}
+
+func doGet(r *gin.Engine, path, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestLoginCreatesSessionAndTokens(t *testing.T) {
+	s := setupAuth(t)
+	pair, err := Login("u1", "alice", "user", testDevice)
+	if err != nil {
+		t.Fatal(err)
+	}
+	access, err := ParseToken(pair.AccessToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if access.Type != "access" || access.UserID != "u1" || access.Role != "user" || access.ID == "" {
+		t.Fatalf("访问令牌声明错误: %+v", access)
+	}
+	refresh, err := ParseToken(pair.RefreshToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if refresh.Type != "refresh" || refresh.SessionID != access.SessionID {
+		t.Fatalf("刷新令牌声明错误: %+v", refresh)
+	}
+	session, err := s.GetSession(access.SessionID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if session.DeviceName != "laptop" || session.RefreshJTI != refresh.ID {
+		t.Fatalf("会话错误: %+v", session)
+	}
+}
+
+func TestLoginSessionsAreIndependent(t *testing.T) {
+	setupAuth(t)
+	first, _ := Login("u1", "alice", "user", testDevice)
+	second, _ := Login("u1", "alice", "user", DeviceInfo{Name: "phone"})
+	sessions, err := ListSessions("u1")
+	if err != nil || len(sessions) != 2 {
+		t.Fatalf("应有两个会话: %v %v", sessions, err)
+	}
+	// 刷新一个设备不影响另一个
+	if _, err = RefreshAccessToken(first.RefreshToken); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = RefreshAccessToken(second.RefreshToken); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRefreshAccessTokenRotates(t *testing.T) {
+	setupAuth(t)
+	pair, _ := Login("u1", "alice", "user", testDevice)
+	next, err := RefreshAccessToken(pair.RefreshToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	old, _ := ParseToken(pair.RefreshToken)
+	rotated, _ := ParseToken(next.RefreshToken)
+	if rotated.ID == old.ID || rotated.ParentID != old.ID || rotated.SessionID != old.SessionID {
+		t.Fatalf("刷新令牌未正确轮换: old=%s new=%+v", old.ID, rotated)
+	}
+	// 新令牌可以继续刷新
+	if _, err = RefreshAccessToken(next.RefreshToken); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRefreshRejectsAccessToken(t *testing.T) {
+	setupAuth(t)
+	pair, _ := Login("u1", "alice", "user", testDevice)
+	if _, err := RefreshAccessToken(pair.AccessToken); err == nil {
+		t.Fatal("访问令牌不能用于刷新")
+	}
+}
+
+func TestRefreshConcurrentWithinGrace(t *testing.T) {
+	setupAuth(t)
+	pair, _ := Login("u1", "alice", "user", testDevice)
+
+	// 多个标签页同时刷新：都成功，且拿到同一个新刷新令牌
+	const n = 8
+	var wg sync.WaitGroup
+	results := make([]*TokenPair, n)
+	errs := make([]error, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], errs[i] = RefreshAccessToken(pair.RefreshToken)
+		}(i)
+	}
+	wg.Wait()
+	var jti string
+	for i := 0; i < n; i++ {
+		if errs[i] != nil {
+			t.Fatalf("并发刷新失败: %v", errs[i])
+		}
+		claims, _ := ParseToken(results[i].RefreshToken)
+		if jti == "" {
+			jti = claims.ID
+		} else if claims.ID != jti {
+			t.Fatalf("并发刷新得到不同的令牌: %s %s", jti, claims.ID)
+		}
+	}
+}
+
+func TestRefreshReuseAfterGraceRevokesFamily(t *testing.T) {
+	s := setupAuth(t)
+	stolen, _ := Login("u1", "alice", "user", testDevice)
+	other, _ := Login("u1", "alice", "user", DeviceInfo{Name: "phone"})
+	if _, err := RefreshAccessToken(stolen.RefreshToken); err != nil {
+		t.Fatal(err)
+	}
+
+	// 把轮换时间拨回宽限期之前
+	old, _ := ParseToken(stolen.RefreshToken)
+	s.mu.Lock()
+	s.refresh[old.ID].value.RotatedAt = time.Now().Add(-2 * RefreshReuseGrace).Unix()
+	s.mu.Unlock()
+
+	if _, err := RefreshAccessToken(stolen.RefreshToken); err == nil {
+		t.Fatal("超过宽限期重复使用应当失败")
+	}
+	if _, err := GetSession(old.SessionID); err == nil {
+		t.Fatal("被盗用的令牌家族应当被撤销")
+	}
+	if len(s.events["u1"]) != 1 {
+		t.Fatalf("应记录一条安全事件，实际 %d", len(s.events["u1"]))
+	}
+	// 其他设备的会话不受影响
+	if _, err := RefreshAccessToken(other.RefreshToken); err != nil {
+		t.Fatalf("其他会话不应受影响: %v", err)
+	}
+}
+
+func TestJWTAuthMiddleware(t *testing.T) {
+	setupAuth(t)
+	r := newAuthRouter()
+	pair, _ := Login("u1", "alice", "editor", testDevice)
+
+	if w := doGet(r, "/api/me", ""); w.Code != http.StatusUnauthorized {
+		t.Fatalf("缺少令牌应返回 401，实际 %d", w.Code)
+	}
+	if w := doGet(r, "/api/me", "not-a-jwt"); w.Code != http.StatusUnauthorized {
+		t.Fatalf("无效令牌应返回 401，实际 %d", w.Code)
+	}
+	if w := doGet(r, "/api/me", pair.RefreshToken); w.Code != http.StatusUnauthorized {
+		t.Fatalf("刷新令牌不能访问接口，实际 %d", w.Code)
+	}
+	w := doGet(r, "/api/me", pair.AccessToken)
+	if w.Code != http.StatusOK {
+		t.Fatalf("有效令牌应返回 200，实际 %d %s", w.Code, w.Body.String())
+	}
+	claims, _ := ParseToken(pair.AccessToken)
+	want := `{"role":"editor","session_id":"` + claims.SessionID + `","user_id":"u1"}`
+	if w.Body.String() != want {
+		t.Fatalf("上下文错误: %s", w.Body.String())
+	}
+}
+
+func TestJWTAuthMiddlewareRejectsLoggedOutToken(t *testing.T) {
+	setupAuth(t)
+	r := newAuthRouter()
+	pair, _ := Login("u1", "alice", "user", testDevice)
+	claims, _ := ParseToken(pair.AccessToken)
+	if err := Logout(claims); err != nil {
+		t.Fatal(err)
+	}
+	if w := doGet(r, "/api/me", pair.AccessToken); w.Code != http.StatusUnauthorized {
+		t.Fatalf("注销后的令牌应返回 401，实际 %d", w.Code)
+	}
+	if _, err := RefreshAccessToken(pair.RefreshToken); err == nil {
+		t.Fatal("注销后刷新令牌应失效")
+	}
+}
+
+func TestJWTAuthMiddlewareRejectsRevokedSession(t *testing.T) {
+	setupAuth(t)
+	r := newAuthRouter()
+	current, _ := Login("u1", "alice", "user", testDevice)
+	other, _ := Login("u1", "alice", "user", DeviceInfo{Name: "phone"})
+	claims, _ := ParseToken(current.AccessToken)
+	if err := RevokeOtherSessions("u1", claims.SessionID); err != nil {
+		t.Fatal(err)
+	}
+	if w := doGet(r, "/api/me", other.AccessToken); w.Code != http.StatusUnauthorized {
+		t.Fatalf("被撤销会话的令牌应返回 401，实际 %d", w.Code)
+	}
+	if w := doGet(r, "/api/me", current.AccessToken); w.Code != http.StatusOK {
+		t.Fatalf("当前会话不应受影响，实际 %d", w.Code)
+	}
+}
+
+func TestJWTAuthMiddlewareSkipsExcludedPaths(t *testing.T) {
+	setupAuth(t)
+	r := gin.New()
+	r.POST("/api/user/login", JWTAuthMiddleware(), func(c *gin.Context) { c.Status(http.StatusNoContent) })
+	req := httptest.NewRequest(http.MethodPost, "/api/user/login", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("登录接口不需要令牌，实际 %d", w.Code)
+	}
+}
+
+func TestMemoryStoreExpiry(t *testing.T) {
+	s := setupAuth(t)
+	now := time.Now()
+	s.SetClock(func() time.Time { return now })
+	pair, _ := Login("u1", "alice", "user", testDevice)
+	claims, _ := ParseToken(pair.AccessToken)
+
+	now = now.Add(RefreshTokenExpiration + time.Second)
+	if _, err := GetSession(claims.SessionID); err != ErrNotFound {
+		t.Fatalf("会话应已过期: %v", err)
+	}
+	if ok, _ := s.SetNX("k", "v", time.Minute); !ok {
+		t.Fatal("SetNX 应当成功")
+	}
+	if ok, _ := s.SetNX("k", "v", time.Minute); ok {
+		t.Fatal("重复 SetNX 应当失败")
+	}
+	now = now.Add(2 * time.Minute)
+	if ok, _ := s.Exists("k"); ok {
+		t.Fatal("键应已过期")
+	}
+}
+
+func TestDisabledUserIsRejected(t *testing.T) {
+	setupAuth(t)
+	r := newAuthRouter()
+	pair, _ := Login("u1", "alice", "user", testDevice)
+	until := time.Now().Add(time.Hour)
+	if err := DisableUser("u1", "spam", &until); err != nil {
+		t.Fatal(err)
+	}
+	if w := doGet(r, "/api/me", pair.AccessToken); w.Code == http.StatusOK {
+		t.Fatal("被禁用用户的访问令牌应被拒绝")
+	}
+	if _, err := RefreshAccessToken(pair.RefreshToken); err == nil {
+		t.Fatal("被禁用用户不能刷新令牌")
+	}
+	if sessions, _ := ListSessions("u1"); len(sessions) != 0 {
+		t.Fatalf("禁用时应撤销所有会话，剩余 %d", len(sessions))
+	}
+
+	// 解除禁用后重新登录即可访问
+	if err := EnableUser("u1"); err != nil {
+		t.Fatal(err)
+	}
+	pair, _ = Login("u1", "alice", "user", testDevice)
+	if w := doGet(r, "/api/me", pair.AccessToken); w.Code != http.StatusOK {
+		t.Fatalf("解除禁用后应可访问，实际 %d", w.Code)
+	}
+}
+
+func TestTemporaryDisableExpires(t *testing.T) {
+	s := setupAuth(t)
+	now := time.Now()
+	s.SetClock(func() time.Time { return now })
+	until := now.Add(time.Hour)
+	if err := DisableUser("u1", "cooldown", &until); err != nil {
+		t.Fatal(err)
+	}
+	if !IsUserDisabled("u1") {
+		t.Fatal("应处于禁用状态")
+	}
+	now = now.Add(time.Hour + time.Second)
+	if IsUserDisabled("u1") {
+		t.Fatal("临时禁用到期后应自动解除")
+	}
+}
+
+func TestHS256SecretRequired(t *testing.T) {
+	conf := config.JWTConfig{
+		ActiveKID: "hs",
+		Keys:      []config.JWTKeyConfig{{KID: "hs", Alg: "HS256", SecretEnv: "TEST_JWT_SECRET_UNSET"}},
+	}
+	if _, err := NewKeySet(conf); err == nil {
+		t.Fatal("未提供 HS256 密钥时应加载失败")
+	}
+	file := t.TempDir() + "/secret"
+	if err := os.WriteFile(file, []byte("file-secret-key-at-least-32-characters\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	conf.Keys[0].SecretFile = file
+	if _, err := NewKeySet(conf); err != nil {
+		t.Fatalf("应从文件读取密钥: %v", err)
+	}
+}
@@ -0,0 +1,238 @@
+package middleware
+
+import (
+	"TestGin/config"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"github.com/golang-jwt/jwt/v5"
+	"math/big"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// signingKey 签名密钥
+type signingKey struct {
+	kid       string
+	method    jwt.SigningMethod
+	signKey   interface{} // 签名用：[]byte / *rsa.PrivateKey / ed25519.PrivateKey
+	verifyKey interface{} // 验签用：[]byte / *rsa.PublicKey / ed25519.PublicKey
+	retiredAt time.Time   // 零值表示未退役
+}
+
+// KeySet 签名密钥集合，支持按 kid 轮换
+type KeySet struct {
+	issuer      string
+	active      *signingKey
+	keys        map[string]*signingKey
+	gracePeriod time.Duration
+}
+
+var keySet *KeySet
+
+// InitJWTKeys 从配置加载签名密钥
+func InitJWTKeys(conf config.JWTConfig) error {
+	ks, err := NewKeySet(conf)
+	if err != nil {
+		return err
+	}
+	keySet = ks
+	return nil
+}
+
+// NewKeySet 根据配置构建密钥集合
+func NewKeySet(conf config.JWTConfig) (*KeySet, error) {
+	ks := &KeySet{
+		issuer:      conf.Issuer,
+		keys:        make(map[string]*signingKey),
+		gracePeriod: conf.GracePeriod,
+	}
+	if ks.gracePeriod <= 0 {
+		ks.gracePeriod = RefreshTokenExpiration
+	}
+	for _, kc := range conf.Keys {
+		if kc.KID == "" {
+			return nil, errors.New("JWT 密钥缺少 kid")
+		}
+		if _, ok := ks.keys[kc.KID]; ok {
+			return nil, fmt.Errorf("JWT 密钥 kid 重复: %s", kc.KID)
+		}
+		key, err := loadSigningKey(kc)
+		if err != nil {
+			return nil, fmt.Errorf("加载 JWT 密钥 %s 失败: %w", kc.KID, err)
+		}
+		ks.keys[kc.KID] = key
+	}
+	active, ok := ks.keys[conf.ActiveKID]
+	if !ok {
+		return nil, fmt.Errorf("未找到当前签名密钥: %s", conf.ActiveKID)
+	}
+	if !active.retiredAt.IsZero() {
+		return nil, fmt.Errorf("当前签名密钥 %s 已退役", conf.ActiveKID)
+	}
+	ks.active = active
+	return ks, nil
+}
+
+// loadSecret 读取 HS256 共享密钥：优先环境变量 SecretEnv，其次文件 SecretFile，都没有时返回错误
+func loadSecret(kc config.JWTKeyConfig) (string, error) {
+	if kc.SecretEnv != "" {
+		if v := os.Getenv(kc.SecretEnv); v != "" {
+			return v, nil
+		}
+	}
+	if kc.SecretFile != "" {
+		data, err := os.ReadFile(kc.SecretFile)
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimSpace(string(data)), nil
+	}
+	return "", fmt.Errorf("未设置环境变量 %s，也未配置 secretfile", kc.SecretEnv)
+}
+
+func loadSigningKey(kc config.JWTKeyConfig) (*signingKey, error) {
+	key := &signingKey{kid: kc.KID}
+	if kc.RetiredAt != "" {
+		t, err := time.Parse(time.RFC3339, kc.RetiredAt)
+		if err != nil {
+			return nil, fmt.Errorf("退役时间格式错误: %w", err)
+		}
+		key.retiredAt = t
+	}
+
+	switch kc.Alg {
+	case "", "HS256":
+		secret, err := loadSecret(kc)
+		if err != nil {
+			return nil, err
+		}
+		if len(secret) < 32 {
+			return nil, errors.New("HS256 密钥长度至少 32 个字符")
+		}
+		key.method = jwt.SigningMethodHS256
+		key.signKey = []byte(secret)
+		key.verifyKey = []byte(secret)
+	case "RS256":
+		pem, err := os.ReadFile(kc.PrivateKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		priv, err := jwt.ParseRSAPrivateKeyFromPEM(pem)
+		if err != nil {
+			return nil, err
+		}
+		key.method = jwt.SigningMethodRS256
+		key.signKey = priv
+		key.verifyKey = &priv.PublicKey
+	case "EdDSA":
+		pem, err := os.ReadFile(kc.PrivateKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		priv, err := jwt.ParseEdPrivateKeyFromPEM(pem)
+		if err != nil {
+			return nil, err
+		}
+		edPriv, ok := priv.(ed25519.PrivateKey)
+		if !ok {
+			return nil, errors.New("不是 Ed25519 私钥")
+		}
+		key.method = jwt.SigningMethodEdDSA
+		key.signKey = edPriv
+		key.verifyKey = edPriv.Public().(ed25519.PublicKey)
+	default:
+		return nil, fmt.Errorf("不支持的签名算法: %s", kc.Alg)
+	}
+	return key, nil
+}
+
+// Sign 使用当前密钥签名，并在头部写入 kid
+func (ks *KeySet) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(ks.active.method, claims)
+	token.Header["kid"] = ks.active.kid
+	return token.SignedString(ks.active.signKey)
+}
+
+// Keyfunc 根据令牌头部的 kid 选择验签密钥
+func (ks *KeySet) Keyfunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	key, ok := ks.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("未知的密钥 kid: %v", token.Header["kid"])
+	}
+	if token.Method.Alg() != key.method.Alg() {
+		return nil, fmt.Errorf("无效的签名方法%v\n", token.Header["alg"])
+	}
+	if !key.retiredAt.IsZero() && time.Now().After(key.retiredAt.Add(ks.gracePeriod)) {
+		return nil, fmt.Errorf("密钥 %s 已过宽限期", kid)
+	}
+	return key.verifyKey, nil
+}
+
+// parserOptions 解析令牌时的校验选项
+func (ks *KeySet) parserOptions() []jwt.ParserOption {
+	opts := []jwt.ParserOption{jwt.WithExpirationRequired()}
+	if ks.issuer != "" {
+		opts = append(opts, jwt.WithIssuer(ks.issuer))
+	}
+	return opts
+}
+
+// JWK 单个公钥 (RFC 7517)
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// JWKSet 公钥集合
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS 导出所有仍可验签的非对称公钥，HS256 共享密钥不会公开
+func (ks *KeySet) JWKS() JWKSet {
+	set := JWKSet{Keys: []JWK{}}
+	now := time.Now()
+	kids := make([]string, 0, len(ks.keys))
+	for kid := range ks.keys {
+		kids = append(kids, kid)
+	}
+	sort.Strings(kids)
+	for _, kid := range kids {
+		key := ks.keys[kid]
+		if !key.retiredAt.IsZero() && now.After(key.retiredAt.Add(ks.gracePeriod)) {
+			continue
+		}
+		jwk := JWK{Kid: kid, Use: "sig", Alg: key.method.Alg()}
+		switch pub := key.verifyKey.(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+		default:
+			continue
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	return set
+}
+
+// GetJWKS 获取当前密钥集合的公钥
+func GetJWKS() JWKSet {
+	return keySet.JWKS()
+}
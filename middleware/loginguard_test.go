@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestLoginLockKeyedOnUser(t *testing.T) {
+	setupAuth(t)
+	key := UserLoginKey("u1")
+	for i := 0; i < loginConf.MaxAccountFailures; i++ {
+		RecordLoginFailure(key, "10.0.0.1")
+	}
+	var blocked *LoginBlockedError
+	if err := CheckLoginAllowed(key, "10.0.0.2"); !errors.As(err, &blocked) || !blocked.Locked {
+		t.Fatalf("用户连续失败后应被锁定，实际 %v", err)
+	}
+	// 以 UUID 作为登录标识的失败不会计入该用户，反之亦然
+	if err := CheckLoginAllowed(IdentifierLoginKey("u1"), "10.0.0.3"); err != nil {
+		t.Fatalf("同名标识不应受用户锁定影响，实际 %v", err)
+	}
+}
+
+func TestLoginSuccessKeepsIPFailures(t *testing.T) {
+	setupAuth(t)
+	ip := "10.0.0.9"
+	for i := 0; i < loginConf.MaxIPFailures-1; i++ {
+		RecordLoginFailure(IdentifierLoginKey("victim"), ip)
+	}
+	// 登录自己的账号成功不能清除 IP 计数
+	ResetLoginFailures(UserLoginKey("attacker"), ip)
+	RecordLoginFailure(IdentifierLoginKey("victim"), ip)
+	var blocked *LoginBlockedError
+	if err := CheckLoginAllowed(UserLoginKey("other"), ip); !errors.As(err, &blocked) || !blocked.Locked {
+		t.Fatalf("IP 失败次数达到上限后应被锁定，实际 %v", err)
+	}
+}
@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"github.com/golang-jwt/jwt/v5"
+	"time"
+)
+
+// MFATokenExpiration 两步验证临时令牌有效期
+const MFATokenExpiration = 5 * time.Minute
+
+// CreateMFAToken 密码校验通过后签发的临时令牌，只能用于 /api/user/login/mfa
+func CreateMFAToken(userID string) (string, error) {
+	claims := Claims{
+		UserID: userID,
+		Type:   "mfa_pending",
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        generateUniqueID(),
+			Issuer:    keySet.issuer,
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(MFATokenExpiration)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	}
+	return keySet.Sign(claims)
+}
+
+// ParseMFAToken 解析两步验证临时令牌
+func ParseMFAToken(tokenString string) (*Claims, error) {
+	claims, err := ParseToken(tokenString)
+	if err != nil {
+		return nil, errors.New("两步验证令牌无效或已过期")
+	}
+	if claims.Type != "mfa_pending" || claims.ID == "" {
+		return nil, errors.New("令牌类型错误")
+	}
+	if IsTokenDenied(claims.ID) {
+		return nil, errors.New("两步验证令牌已使用")
+	}
+	return claims, nil
+}
+
+// ConsumeMFAToken 验证成功后作废临时令牌，保证只能使用一次
+func ConsumeMFAToken(claims *Claims) bool {
+	ttl := time.Until(claims.ExpiresAt.Time)
+	if ttl <= 0 {
+		return false
+	}
+	ok, err := store.SetNX(denylistKey(claims.ID), claims.UserID, ttl)
+	return err == nil && ok
+}
+
+// MarkTOTPUsed 记录已使用的 TOTP 时间步，防止同一动态码被重放
+func MarkTOTPUsed(UUID string, step int64) bool {
+	key := fmt.Sprintf("totp_used:%s:%d", UUID, step)
+	ok, err := store.SetNX(key, "1", 3*time.Minute)
+	return err == nil && ok
+}
@@ -0,0 +1,86 @@
+package middleware
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strings"
+)
+
+// PATPrefix 个人访问令牌前缀，用于和 JWT 区分
+const PATPrefix = "tgp_"
+
+// PATIdentity 个人访问令牌对应的身份
+type PATIdentity struct {
+	TokenID  uint
+	UserID   string
+	Username string
+	Role     string
+	Scopes   []string
+}
+
+// PATAuthenticator 校验个人访问令牌，令牌保存在数据库中，由 api 包注入实现
+type PATAuthenticator func(token string) (*PATIdentity, error)
+
+var patAuthenticator PATAuthenticator
+
+// SetPATAuthenticator 设置个人访问令牌校验函数
+func SetPATAuthenticator(fn PATAuthenticator) {
+	patAuthenticator = fn
+}
+
+// IsPAT 是否为个人访问令牌
+func IsPAT(token string) bool {
+	return strings.HasPrefix(token, PATPrefix)
+}
+
+// authenticatePAT 校验个人访问令牌并写入上下文，不创建会话
+func authenticatePAT(c *gin.Context, token string) bool {
+	if patAuthenticator == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "不支持个人访问令牌"})
+		c.Abort()
+		return false
+	}
+	identity, err := patAuthenticator(token)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		c.Abort()
+		return false
+	}
+	if IsUserDisabled(identity.UserID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": ErrAccountDisabled.Error()})
+		c.Abort()
+		return false
+	}
+	c.Set("userID", identity.UserID)
+	c.Set("actorID", identity.UserID)
+	c.Set("username", identity.Username)
+	c.Set("role", identity.Role)
+	c.Set("tokenID", identity.TokenID)
+	c.Set("scopes", identity.Scopes)
+	return true
+}
+
+// ScopesAllow 判断令牌权限范围是否包含权限，支持 "*" 和 "article:*" 通配
+func ScopesAllow(scopes []string, permission string) bool {
+	set := make(map[string]bool, len(scopes))
+	for _, s := range scopes {
+		set[s] = true
+	}
+	return matchPermission(set, permission)
+}
+
+// RequireSession 要求通过账号持有人的登录会话访问，个人访问令牌与模拟登录令牌不能管理令牌、会话、密码等
+func RequireSession() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := c.Get("scopes"); ok || c.GetString("sessionID") == "" {
+			Error(c, http.StatusForbidden, errors.New("该操作需要登录会话，不能使用个人访问令牌"))
+			return
+		}
+		if c.GetBool("impersonating") {
+			Error(c, http.StatusForbidden, errors.New("模拟登录时不能执行该操作"))
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,108 @@
+package middleware
+
+import (
+	"TestGin/config"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strings"
+)
+
+// 角色 -> 权限集合，由配置加载，新增角色无需改代码
+var rolePermissions = map[string]map[string]bool{}
+
+// InitRBAC 加载角色权限配置
+func InitRBAC(conf config.RBACConfig) {
+	roles := make(map[string]map[string]bool, len(conf.Roles))
+	for role, perms := range conf.Roles {
+		set := make(map[string]bool, len(perms))
+		for _, p := range perms {
+			set[p] = true
+		}
+		roles[strings.ToLower(role)] = set
+	}
+	rolePermissions = roles
+}
+
+// HasRole 角色是否在配置中定义
+func HasRole(role string) bool {
+	_, ok := rolePermissions[strings.ToLower(role)]
+	return ok
+}
+
+// HasPermission 判断角色是否拥有权限，支持 "*" 和 "article:*" 通配
+func HasPermission(role, permission string) bool {
+	perms, ok := rolePermissions[strings.ToLower(role)]
+	if !ok {
+		return false
+	}
+	return matchPermission(perms, permission)
+}
+
+// matchPermission 判断权限集合是否包含权限
+func matchPermission(perms map[string]bool, permission string) bool {
+	if perms["*"] || perms[permission] {
+		return true
+	}
+	if i := strings.Index(permission, ":"); i > 0 {
+		return perms[permission[:i]+":*"]
+	}
+	return false
+}
+
+// Can 判断当前请求能否使用权限：角色拥有该权限，使用个人访问令牌时还必须在令牌权限范围内。
+// 用于处理函数中按资源状态决定所需权限的场景，与 RequirePermission 的判断一致
+func Can(c *gin.Context, permission string) bool {
+	if !HasPermission(c.GetString("role"), permission) {
+		return false
+	}
+	if scopes, ok := c.Get("scopes"); ok {
+		return ScopesAllow(scopes.([]string), permission)
+	}
+	return true
+}
+
+// RequirePermission 要求当前用户拥有全部指定权限，需放在 JWTAuthMiddleware 之后。
+// 使用个人访问令牌时，权限还必须在令牌的权限范围内
+func RequirePermission(permissions ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role, ok := c.Get("role")
+		if !ok {
+			Error(c, http.StatusUnauthorized, errors.New("未登录"))
+			return
+		}
+		for _, p := range permissions {
+			if !HasPermission(role.(string), p) {
+				Error(c, http.StatusForbidden, errors.New("权限不足: "+p))
+				return
+			}
+		}
+		if scopes, ok := c.Get("scopes"); ok {
+			for _, p := range permissions {
+				if !ScopesAllow(scopes.([]string), p) {
+					Error(c, http.StatusForbidden, errors.New("令牌权限范围不足: "+p))
+					return
+				}
+			}
+		}
+		c.Next()
+	}
+}
+
+// RequireRole 要求当前用户属于指定角色之一
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role, ok := c.Get("role")
+		if !ok {
+			Error(c, http.StatusUnauthorized, errors.New("未登录"))
+			return
+		}
+		for _, r := range roles {
+			if strings.EqualFold(role.(string), r) {
+				c.Next()
+				return
+			}
+		}
+		Error(c, http.StatusForbidden, errors.New("权限不足"))
+	}
+}
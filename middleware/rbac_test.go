@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"TestGin/config"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestCanRespectsTokenScopes(t *testing.T) {
+	InitRBAC(config.RBACConfig{Roles: map[string][]string{"editor": {"article:*"}}})
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Set("role", "editor")
+	if !Can(c, "article:publish") {
+		t.Fatal("登录会话应按角色判断权限")
+	}
+	c.Set("scopes", []string{"article:read"})
+	if Can(c, "article:publish") {
+		t.Fatal("个人访问令牌不能超出权限范围")
+	}
+	if !Can(c, "article:read") {
+		t.Fatal("权限范围内的权限应允许")
+	}
+}
@@ -0,0 +1,123 @@
+package middleware
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+)
+
+// 刷新令牌家族：一次登录（一个会话）签发的所有刷新令牌属于同一家族，家族 ID 即会话 ID。
+// 每个刷新令牌有自己的 jti 和父令牌 jti，轮换时父令牌记录子令牌 jti。
+// 已轮换的令牌在宽限期内再次使用视为并发刷新；超过宽限期视为令牌被盗用，只撤销该家族。
+
+// RefreshReuseGrace 已轮换的刷新令牌仍可使用的宽限期，用于覆盖多个标签页同时刷新
+const RefreshReuseGrace = 30 * time.Second
+
+// storeRefreshRecord 记录新签发的刷新令牌并设为会话当前令牌
+func storeRefreshRecord(session *Session, jti, parent string) error {
+	record := &RefreshRecord{SessionID: session.ID, UserID: session.UserID, Parent: parent}
+	if err := store.SaveRefreshToken(jti, record, RefreshTokenExpiration); err != nil {
+		return fmt.Errorf("缓存刷新令牌失败: %v", err)
+	}
+	session.RefreshJTI = jti
+	return nil
+}
+
+// getRefreshRecord 获取刷新令牌记录
+func getRefreshRecord(jti string) (*RefreshRecord, error) {
+	record, err := store.GetRefreshToken(jti)
+	if errors.Is(err, ErrNotFound) {
+		return nil, errors.New("刷新令牌已过期")
+	}
+	return record, err
+}
+
+// rotateRefreshToken 轮换刷新令牌，返回会话当前令牌的 jti 及其父令牌 jti
+func rotateRefreshToken(session *Session, jti string) (string, string, error) {
+	record, err := getRefreshRecord(jti)
+	if err != nil {
+		return "", "", err
+	}
+	if record.SessionID != session.ID || record.UserID != session.UserID {
+		return "", "", errors.New("刷新令牌无效")
+	}
+
+	// 当前令牌：抢占轮换权，保证并发请求中只有一个完成轮换
+	if record.Child == "" {
+		child := generateUniqueID()
+		ok, err := store.ClaimRefreshRotation(jti, child, time.Now().Unix())
+		if err != nil {
+			return "", "", err
+		}
+		if ok {
+			if err = storeRefreshRecord(session, child, jti); err != nil {
+				return "", "", err
+			}
+			return child, jti, nil
+		}
+		// 其他请求刚刚完成了轮换，按宽限期处理
+		if record, err = getRefreshRecord(jti); err != nil {
+			return "", "", err
+		}
+	}
+
+	// 已轮换的令牌：宽限期内视为并发刷新，重新签发会话当前令牌
+	if record.RotatedAt == 0 || time.Since(time.Unix(record.RotatedAt, 0)) <= RefreshReuseGrace {
+		current, err := GetSession(session.ID)
+		if err != nil {
+			return "", "", errors.New("会话已失效")
+		}
+		currentRecord, err := getRefreshRecord(current.RefreshJTI)
+		if err != nil {
+			return "", "", err
+		}
+		return current.RefreshJTI, currentRecord.Parent, nil
+	}
+
+	// 超过宽限期再次使用：令牌被盗用，仅撤销该家族
+	if err = deleteSessions(session.UserID, session.ID); err != nil {
+		log.Printf("撤销令牌家族失败: %v", err)
+	}
+	recordSecurityEvent(session, AuthEventRefreshReuse, fmt.Sprintf("已轮换的刷新令牌 %s 被再次使用", jti))
+	return "", "", errors.New("检测到刷新令牌重复使用，该会话已被撤销")
+}
+
+// SecurityEvent 安全事件
+type SecurityEvent struct {
+	UserID    string `json:"user_id"`
+	SessionID string `json:"session_id"`
+	Type      string `json:"type"`
+	Detail    string `json:"detail"`
+	Time      int64  `json:"time"`
+}
+
+// recordSecurityEvent 写入安全事件，每个用户保留最近 100 条；同时写入审计日志，
+// 此时没有请求上下文，IP 与 User-Agent 取自会话登录时的记录
+func recordSecurityEvent(session *Session, eventType, detail string) {
+	event := SecurityEvent{
+		UserID:    session.UserID,
+		SessionID: session.ID,
+		Type:      eventType,
+		Detail:    detail,
+		Time:      time.Now().Unix(),
+	}
+	log.Printf("⚠️ 安全事件: 用户 %s 会话 %s %s: %s", session.UserID, session.ID, eventType, detail)
+	RecordAuthEvent(&AuthEvent{
+		Type:      eventType,
+		UserID:    session.UserID,
+		SessionID: session.ID,
+		IP:        session.IP,
+		UserAgent: session.UserAgent,
+		Reason:    detail,
+		Time:      time.Unix(event.Time, 0),
+	})
+	data, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+	if err = store.AppendSecurityEvent(session.UserID, data, 100, 30*24*time.Hour); err != nil {
+		log.Printf("写入安全事件失败: %v", err)
+	}
+}
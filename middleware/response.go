@@ -7,6 +7,14 @@ import (
 	"time"
 )
 
+// 业务错误码，与 HTTP 状态码区分
+const (
+	CodeLoginThrottled  = 42901 // 登录失败过于频繁，需等待退避时间
+	CodeAccountLocked   = 42301 // 多次登录失败，账号或 IP 被临时锁定
+	CodeAccountDisabled = 40301 // 账号被管理员禁用
+	CodeCSRFRejected    = 40302 // 来源或 CSRF 令牌校验失败
+)
+
 type Response struct {
 	Code    int         `json:"code"`    // 自定义状态码
 	Message string      `json:"message"` // 提示信息
@@ -33,8 +41,13 @@ func Success(c *gin.Context, data interface{}) {
 
 // Error httpCode int不是必要参数
 func Error(c *gin.Context, code int, err error) {
+	ErrorWithCode(c, code, code, err)
+}
+
+// ErrorWithCode 返回 HTTP 状态码与业务码不同的错误
+func ErrorWithCode(c *gin.Context, httpCode, code int, err error) {
 	//不写http_code就默认使用200 AbortWithStatusJSON中断请求并且返回json数据，不会执行后续处理
-	c.AbortWithStatusJSON(code, gin.H{
+	c.AbortWithStatusJSON(httpCode, gin.H{
 		"code":    code,
 		"message": err.Error(),
 		"data":    nil,
@@ -0,0 +1,154 @@
+package middleware
+
+import (
+	"TestGin/util"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"log"
+	"sort"
+	"time"
+)
+
+// DeviceInfo 登录设备信息
+type DeviceInfo struct {
+	Name      string // 设备名称，由客户端通过 X-Device-Name 请求头提供
+	UserAgent string
+	IP        string
+	Country   string // 由本地 GeoIP 数据库查询，未知为空
+}
+
+// Fingerprint 设备指纹：设备名与 User-Agent 的 SHA256
+func (d DeviceInfo) Fingerprint() string {
+	sum := sha256.Sum256([]byte(d.Name + "\n" + d.UserAgent))
+	return hex.EncodeToString(sum[:])
+}
+
+// Network IP 所在网段（IPv4 /24，IPv6 /48）
+func (d DeviceInfo) Network() string {
+	return util.IPNetwork(d.IP)
+}
+
+// Session 登录会话，每次登录创建一个
+type Session struct {
+	ID         string `json:"id"`
+	UserID     string `json:"user_id"`
+	Username   string `json:"username"`
+	Role       string `json:"role"`
+	DeviceName string `json:"device_name"`
+	UserAgent  string `json:"user_agent"`
+	IP         string `json:"ip"`
+	CreatedAt  int64  `json:"created_at"`
+	LastActive int64  `json:"last_active"`
+	RefreshJTI string `json:"-"` // 当前有效刷新令牌的 jti
+}
+
+// NewDeviceInfo 从请求中提取设备信息
+func NewDeviceInfo(c *gin.Context) DeviceInfo {
+	return DeviceInfo{
+		Name:      c.GetHeader("X-Device-Name"),
+		UserAgent: c.Request.UserAgent(),
+		IP:        c.ClientIP(),
+	}
+}
+
+// createSession 创建会话并加入用户的会话集合
+func createSession(UUID, username, role string, device DeviceInfo) (*Session, error) {
+	now := time.Now().Unix()
+	s := &Session{
+		ID:         generateUniqueID(),
+		UserID:     UUID,
+		Username:   username,
+		Role:       role,
+		DeviceName: device.Name,
+		UserAgent:  device.UserAgent,
+		IP:         device.IP,
+		CreatedAt:  now,
+		LastActive: now,
+	}
+	if s.ID == "" {
+		return nil, errors.New("生成会话ID失败")
+	}
+	if err := store.CreateSession(s, RefreshTokenExpiration); err != nil {
+		return nil, fmt.Errorf("创建会话失败: %v", err)
+	}
+	return s, nil
+}
+
+// GetSession 获取会话，不存在时返回 ErrNotFound
+func GetSession(sessionID string) (*Session, error) {
+	return store.GetSession(sessionID)
+}
+
+// touchSession 更新会话最后活跃时间
+func touchSession(sessionID string) {
+	if err := store.TouchSession(sessionID, time.Now().Unix()); err != nil {
+		log.Printf("更新会话活跃时间失败: %v", err)
+	}
+}
+
+// ListSessions 列出用户所有有效会话，按最后活跃时间倒序
+func ListSessions(UUID string) ([]Session, error) {
+	ids, err := store.ListSessionIDs(UUID)
+	if err != nil {
+		return nil, err
+	}
+	sessions := make([]Session, 0, len(ids))
+	for _, id := range ids {
+		s, err := GetSession(id)
+		if errors.Is(err, ErrNotFound) {
+			// 会话已过期，顺便清理集合
+			store.DeleteSessions(UUID, id)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, *s)
+	}
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].LastActive > sessions[j].LastActive
+	})
+	return sessions, nil
+}
+
+// RevokeSession 撤销用户的单个会话
+func RevokeSession(UUID, sessionID string) error {
+	s, err := GetSession(sessionID)
+	if errors.Is(err, ErrNotFound) || (err == nil && s.UserID != UUID) {
+		return errors.New("会话不存在")
+	}
+	if err != nil {
+		return err
+	}
+	return deleteSessions(UUID, sessionID)
+}
+
+// RevokeOtherSessions 撤销除当前会话外的所有会话
+func RevokeOtherSessions(UUID, currentSessionID string) error {
+	ids, err := store.ListSessionIDs(UUID)
+	if err != nil {
+		return err
+	}
+	others := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if id != currentSessionID {
+			others = append(others, id)
+		}
+	}
+	return deleteSessions(UUID, others...)
+}
+
+// deleteSessions 删除会话，会话内所有刷新令牌随之失效
+func deleteSessions(UUID string, sessionIDs ...string) error {
+	if len(sessionIDs) == 0 {
+		return nil
+	}
+	if err := store.DeleteSessions(UUID, sessionIDs...); err != nil {
+		return fmt.Errorf("删除会话失败: %v", err)
+	}
+	log.Printf("🔒 用户 %s 的 %d 个会话已被撤销", UUID, len(sessionIDs))
+	return nil
+}
@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+)
+
+// 被禁用的用户在会话存储中有一个标记，JWTAuthMiddleware 与 RefreshAccessToken 据此拒绝请求；
+// 数据库中的 User.Status 仍是登录时的判断依据。临时禁用的标记随到期时间自动过期。
+
+// ErrAccountDisabled 账号已被禁用
+var ErrAccountDisabled = errors.New("账号已被禁用")
+
+func userDisabledKey(UUID string) string {
+	return fmt.Sprintf("user_disabled:%s", UUID)
+}
+
+// DisableUser 标记用户为禁用并立即撤销其所有会话，until 为空表示永久禁用
+func DisableUser(UUID, reason string, until *time.Time) error {
+	var ttl time.Duration
+	if until != nil {
+		if ttl = time.Until(*until); ttl <= 0 {
+			return errors.New("解禁时间必须晚于当前时间")
+		}
+	}
+	if err := store.Set(userDisabledKey(UUID), reason, ttl); err != nil {
+		return fmt.Errorf("写入禁用标记失败: %v", err)
+	}
+	log.Printf("⛔ 用户 %s 已被禁用: %s", UUID, reason)
+	return RevokeAllUserTokens(UUID)
+}
+
+// EnableUser 清除禁用标记
+func EnableUser(UUID string) error {
+	return store.Del(userDisabledKey(UUID))
+}
+
+// IsUserDisabled 用户是否被禁用，查询失败时按已禁用处理
+func IsUserDisabled(UUID string) bool {
+	disabled, err := store.Exists(userDisabledKey(UUID))
+	return err != nil || disabled
+}
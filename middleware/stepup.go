@@ -0,0 +1,82 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// StepUpExpiration 异常登录邮件验证码有效期
+const StepUpExpiration = 10 * time.Minute
+
+func stepUpCodeKey(jti string) string {
+	return fmt.Sprintf("stepup_code:%s", jti)
+}
+
+// CreateStepUpToken 异常登录需要二次验证时签发临时令牌与 6 位邮件验证码，只能用于 /api/user/login/step-up。
+// 库中只保存验证码的 SHA256
+func CreateStepUpToken(userID string) (token, code string, err error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1000000))
+	if err != nil {
+		return "", "", err
+	}
+	code = fmt.Sprintf("%06d", n.Int64())
+	claims := Claims{
+		UserID: userID,
+		Type:   "login_stepup",
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        generateUniqueID(),
+			Issuer:    keySet.issuer,
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(StepUpExpiration)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	}
+	if token, err = keySet.Sign(claims); err != nil {
+		return "", "", err
+	}
+	if err = store.Set(stepUpCodeKey(claims.ID), hashStepUpCode(code), StepUpExpiration); err != nil {
+		return "", "", err
+	}
+	return token, code, nil
+}
+
+// ParseStepUpToken 解析二次验证临时令牌
+func ParseStepUpToken(tokenString string) (*Claims, error) {
+	claims, err := ParseToken(tokenString)
+	if err != nil {
+		return nil, errors.New("验证令牌无效或已过期")
+	}
+	if claims.Type != "login_stepup" || claims.ID == "" {
+		return nil, errors.New("令牌类型错误")
+	}
+	if IsTokenDenied(claims.ID) {
+		return nil, errors.New("验证令牌已使用")
+	}
+	return claims, nil
+}
+
+// VerifyStepUpCode 校验邮件验证码，通过后作废临时令牌，保证只能使用一次
+func VerifyStepUpCode(claims *Claims, code string) bool {
+	stored, err := store.GetDel(stepUpCodeKey(claims.ID))
+	if err != nil {
+		return false
+	}
+	if subtle.ConstantTimeCompare([]byte(stored), []byte(hashStepUpCode(code))) != 1 {
+		// 验证码错误时放回，失败次数由登录防暴力破解限制
+		store.Set(stepUpCodeKey(claims.ID), stored, time.Until(claims.ExpiresAt.Time))
+		return false
+	}
+	return ConsumeMFAToken(claims)
+}
+
+func hashStepUpCode(code string) string {
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrNotFound 存储中不存在该记录（或已过期）
+var ErrNotFound = errors.New("记录不存在或已过期")
+
+// SessionStore 认证状态存储：登录会话、刷新令牌家族、安全事件，
+// 以及令牌黑名单、一次性令牌、登录失败计数等带过期时间的短期键值。
+// 生产环境使用 Redis 实现，单机开发与测试可使用内存实现。
+type SessionStore interface {
+	// CreateSession 保存会话并加入用户的会话集合
+	CreateSession(s *Session, ttl time.Duration) error
+	// GetSession 获取会话，不存在时返回 ErrNotFound
+	GetSession(sessionID string) (*Session, error)
+	// TouchSession 更新会话最后活跃时间
+	TouchSession(sessionID string, lastActive int64) error
+	// ExtendSession 会话及用户会话集合续期
+	ExtendSession(s *Session, ttl time.Duration) error
+	// ListSessionIDs 用户的会话 ID（可能包含已过期的会话）
+	ListSessionIDs(UUID string) ([]string, error)
+	// DeleteSessions 删除会话并从用户的会话集合中移除
+	DeleteSessions(UUID string, sessionIDs ...string) error
+
+	// SaveRefreshToken 保存刷新令牌记录，并设为所属会话的当前令牌
+	SaveRefreshToken(jti string, record *RefreshRecord, ttl time.Duration) error
+	// GetRefreshToken 获取刷新令牌记录，不存在时返回 ErrNotFound
+	GetRefreshToken(jti string) (*RefreshRecord, error)
+	// ClaimRefreshRotation 原子地为尚未轮换的刷新令牌记录子令牌，已轮换时返回 false
+	ClaimRefreshRotation(jti, child string, rotatedAt int64) (bool, error)
+
+	// AppendSecurityEvent 追加安全事件，每个用户最多保留 max 条
+	AppendSecurityEvent(UUID string, event []byte, max int, ttl time.Duration) error
+
+	// Set 写入键值
+	Set(key, value string, ttl time.Duration) error
+	// SetNX 键不存在时写入，返回是否写入
+	SetNX(key, value string, ttl time.Duration) (bool, error)
+	// GetDel 读取并删除，不存在时返回 ErrNotFound
+	GetDel(key string) (string, error)
+	// DelIfEqual 值等于 value 时原子地删除，返回是否删除
+	DelIfEqual(key, value string) (bool, error)
+	// Exists 键是否存在
+	Exists(key string) (bool, error)
+	// TTL 剩余有效期，不存在时返回 0
+	TTL(key string) (time.Duration, error)
+	// Incr 计数加一，首次创建时设置有效期
+	Incr(key string, ttl time.Duration) (int64, error)
+	// Del 删除键
+	Del(keys ...string) error
+}
+
+// RefreshRecord 刷新令牌记录
+type RefreshRecord struct {
+	SessionID string
+	UserID    string
+	Parent    string // 父令牌 jti
+	Child     string // 轮换后的子令牌 jti，为空表示当前令牌
+	RotatedAt int64
+}
@@ -0,0 +1,269 @@
+package middleware
+
+import (
+	"strconv"
+	"sync"
+	"time"
+)
+
+// MemorySessionStore 进程内的认证状态存储，用于单机开发与测试，重启后数据丢失，多实例部署不能使用
+type MemorySessionStore struct {
+	mu       sync.Mutex
+	now      func() time.Time // 便于测试时控制时间
+	sessions map[string]*memoryEntry[Session]
+	users    map[string]map[string]bool // UUID -> 会话 ID 集合
+	refresh  map[string]*memoryEntry[RefreshRecord]
+	events   map[string][][]byte
+	values   map[string]*memoryEntry[string]
+}
+
+// memoryEntry 带过期时间的记录，零值表示永不过期
+type memoryEntry[T any] struct {
+	value     T
+	expiresAt time.Time
+}
+
+func (e *memoryEntry[T]) expired(now time.Time) bool {
+	return !e.expiresAt.IsZero() && !now.Before(e.expiresAt)
+}
+
+// NewMemorySessionStore 创建内存存储
+func NewMemorySessionStore() *MemorySessionStore {
+	return &MemorySessionStore{
+		now:      time.Now,
+		sessions: make(map[string]*memoryEntry[Session]),
+		users:    make(map[string]map[string]bool),
+		refresh:  make(map[string]*memoryEntry[RefreshRecord]),
+		events:   make(map[string][][]byte),
+		values:   make(map[string]*memoryEntry[string]),
+	}
+}
+
+// SetClock 替换时钟，测试中用于模拟时间流逝
+func (m *MemorySessionStore) SetClock(now func() time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.now = now
+}
+
+func (m *MemorySessionStore) expiry(ttl time.Duration) time.Time {
+	if ttl <= 0 {
+		return time.Time{}
+	}
+	return m.now().Add(ttl)
+}
+
+// session 获取未过期的会话，调用方需持有锁
+func (m *MemorySessionStore) session(sessionID string) *memoryEntry[Session] {
+	e, ok := m.sessions[sessionID]
+	if !ok {
+		return nil
+	}
+	if e.expired(m.now()) {
+		delete(m.sessions, sessionID)
+		return nil
+	}
+	return e
+}
+
+func (m *MemorySessionStore) CreateSession(s *Session, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sessions[s.ID] = &memoryEntry[Session]{value: *s, expiresAt: m.expiry(ttl)}
+	if m.users[s.UserID] == nil {
+		m.users[s.UserID] = make(map[string]bool)
+	}
+	m.users[s.UserID][s.ID] = true
+	return nil
+}
+
+func (m *MemorySessionStore) GetSession(sessionID string) (*Session, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e := m.session(sessionID)
+	if e == nil {
+		return nil, ErrNotFound
+	}
+	s := e.value
+	return &s, nil
+}
+
+func (m *MemorySessionStore) TouchSession(sessionID string, lastActive int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if e := m.session(sessionID); e != nil {
+		e.value.LastActive = lastActive
+	}
+	return nil
+}
+
+func (m *MemorySessionStore) ExtendSession(s *Session, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if e := m.session(s.ID); e != nil {
+		e.expiresAt = m.expiry(ttl)
+	}
+	return nil
+}
+
+func (m *MemorySessionStore) ListSessionIDs(UUID string) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	ids := make([]string, 0, len(m.users[UUID]))
+	for id := range m.users[UUID] {
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+func (m *MemorySessionStore) DeleteSessions(UUID string, sessionIDs ...string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, id := range sessionIDs {
+		delete(m.sessions, id)
+		delete(m.users[UUID], id)
+	}
+	if len(m.users[UUID]) == 0 {
+		delete(m.users, UUID)
+	}
+	return nil
+}
+
+func (m *MemorySessionStore) SaveRefreshToken(jti string, record *RefreshRecord, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.refresh[jti] = &memoryEntry[RefreshRecord]{
+		value:     RefreshRecord{SessionID: record.SessionID, UserID: record.UserID, Parent: record.Parent},
+		expiresAt: m.expiry(ttl),
+	}
+	if e := m.session(record.SessionID); e != nil {
+		e.value.RefreshJTI = jti
+	}
+	return nil
+}
+
+func (m *MemorySessionStore) GetRefreshToken(jti string) (*RefreshRecord, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e, ok := m.refresh[jti]
+	if !ok || e.expired(m.now()) {
+		delete(m.refresh, jti)
+		return nil, ErrNotFound
+	}
+	r := e.value
+	return &r, nil
+}
+
+func (m *MemorySessionStore) ClaimRefreshRotation(jti, child string, rotatedAt int64) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e, ok := m.refresh[jti]
+	if !ok || e.expired(m.now()) || e.value.Child != "" {
+		return false, nil
+	}
+	e.value.Child = child
+	e.value.RotatedAt = rotatedAt
+	return true, nil
+}
+
+func (m *MemorySessionStore) AppendSecurityEvent(UUID string, event []byte, max int, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	events := append([][]byte{event}, m.events[UUID]...)
+	if len(events) > max {
+		events = events[:max]
+	}
+	m.events[UUID] = events
+	return nil
+}
+
+// value 获取未过期的键值，调用方需持有锁
+func (m *MemorySessionStore) value(key string) *memoryEntry[string] {
+	e, ok := m.values[key]
+	if !ok {
+		return nil
+	}
+	if e.expired(m.now()) {
+		delete(m.values, key)
+		return nil
+	}
+	return e
+}
+
+func (m *MemorySessionStore) Set(key, value string, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.values[key] = &memoryEntry[string]{value: value, expiresAt: m.expiry(ttl)}
+	return nil
+}
+
+func (m *MemorySessionStore) SetNX(key, value string, ttl time.Duration) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.value(key) != nil {
+		return false, nil
+	}
+	m.values[key] = &memoryEntry[string]{value: value, expiresAt: m.expiry(ttl)}
+	return true, nil
+}
+
+func (m *MemorySessionStore) GetDel(key string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e := m.value(key)
+	if e == nil {
+		return "", ErrNotFound
+	}
+	delete(m.values, key)
+	return e.value, nil
+}
+
+func (m *MemorySessionStore) DelIfEqual(key, value string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e := m.value(key)
+	if e == nil || e.value != value {
+		return false, nil
+	}
+	delete(m.values, key)
+	return true, nil
+}
+
+func (m *MemorySessionStore) Exists(key string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.value(key) != nil, nil
+}
+
+func (m *MemorySessionStore) TTL(key string) (time.Duration, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e := m.value(key)
+	if e == nil || e.expiresAt.IsZero() {
+		return 0, nil
+	}
+	return e.expiresAt.Sub(m.now()), nil
+}
+
+func (m *MemorySessionStore) Incr(key string, ttl time.Duration) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e := m.value(key)
+	if e == nil {
+		m.values[key] = &memoryEntry[string]{value: "1", expiresAt: m.expiry(ttl)}
+		return 1, nil
+	}
+	count, _ := strconv.ParseInt(e.value, 10, 64)
+	count++
+	e.value = strconv.FormatInt(count, 10)
+	return count, nil
+}
+
+func (m *MemorySessionStore) Del(keys ...string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, key := range keys {
+		delete(m.values, key)
+	}
+	return nil
+}
@@ -0,0 +1,222 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"github.com/redis/go-redis/v9"
+	"strconv"
+	"time"
+)
+
+// RedisSessionStore 基于 Redis 的认证状态存储
+type RedisSessionStore struct {
+	rdb *redis.Client
+}
+
+// NewRedisSessionStore 创建 Redis 存储
+func NewRedisSessionStore(rdb *redis.Client) *RedisSessionStore {
+	return &RedisSessionStore{rdb: rdb}
+}
+
+func sessionKey(sessionID string) string {
+	return fmt.Sprintf("session:%s", sessionID)
+}
+
+func userSessionsKey(UUID string) string {
+	return fmt.Sprintf("sessions:%s", UUID)
+}
+
+func refreshKey(jti string) string {
+	return fmt.Sprintf("refresh:%s", jti)
+}
+
+func securityEventsKey(UUID string) string {
+	return fmt.Sprintf("security_events:%s", UUID)
+}
+
+// hsetIfExistsScript 仅在键存在时写入哈希字段。HSET 不会改动已有键的过期时间，
+// 而会话过期或被撤销后直接 HSET 会重新创建一个没有 TTL 的残缺会话
+var hsetIfExistsScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 1 then
+	return redis.call('HSET', KEYS[1], ARGV[1], ARGV[2])
+end
+return 0
+`)
+
+// delIfEqualScript 值相等时才删除，比较与删除之间不会被其他命令插入
+var delIfEqualScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+func (r *RedisSessionStore) CreateSession(s *Session, ttl time.Duration) error {
+	pipe := r.rdb.TxPipeline()
+	pipe.HSet(ctx, sessionKey(s.ID), map[string]interface{}{
+		"user_id":     s.UserID,
+		"username":    s.Username,
+		"role":        s.Role,
+		"device_name": s.DeviceName,
+		"user_agent":  s.UserAgent,
+		"ip":          s.IP,
+		"created_at":  s.CreatedAt,
+		"last_active": s.LastActive,
+	})
+	pipe.Expire(ctx, sessionKey(s.ID), ttl)
+	pipe.SAdd(ctx, userSessionsKey(s.UserID), s.ID)
+	pipe.Expire(ctx, userSessionsKey(s.UserID), ttl)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+func (r *RedisSessionStore) GetSession(sessionID string) (*Session, error) {
+	m, err := r.rdb.HGetAll(ctx, sessionKey(sessionID)).Result()
+	if err != nil {
+		return nil, err
+	}
+	if len(m) == 0 {
+		return nil, ErrNotFound
+	}
+	createdAt, _ := strconv.ParseInt(m["created_at"], 10, 64)
+	lastActive, _ := strconv.ParseInt(m["last_active"], 10, 64)
+	return &Session{
+		ID:         sessionID,
+		UserID:     m["user_id"],
+		Username:   m["username"],
+		Role:       m["role"],
+		DeviceName: m["device_name"],
+		UserAgent:  m["user_agent"],
+		IP:         m["ip"],
+		CreatedAt:  createdAt,
+		LastActive: lastActive,
+		RefreshJTI: m["refresh_jti"],
+	}, nil
+}
+
+func (r *RedisSessionStore) TouchSession(sessionID string, lastActive int64) error {
+	return hsetIfExistsScript.Run(ctx, r.rdb, []string{sessionKey(sessionID)}, "last_active", lastActive).Err()
+}
+
+func (r *RedisSessionStore) ExtendSession(s *Session, ttl time.Duration) error {
+	pipe := r.rdb.TxPipeline()
+	pipe.Expire(ctx, sessionKey(s.ID), ttl)
+	pipe.Expire(ctx, userSessionsKey(s.UserID), ttl)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+func (r *RedisSessionStore) ListSessionIDs(UUID string) ([]string, error) {
+	return r.rdb.SMembers(ctx, userSessionsKey(UUID)).Result()
+}
+
+func (r *RedisSessionStore) DeleteSessions(UUID string, sessionIDs ...string) error {
+	pipe := r.rdb.TxPipeline()
+	for _, id := range sessionIDs {
+		pipe.Del(ctx, sessionKey(id))
+		pipe.SRem(ctx, userSessionsKey(UUID), id)
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+func (r *RedisSessionStore) SaveRefreshToken(jti string, record *RefreshRecord, ttl time.Duration) error {
+	pipe := r.rdb.TxPipeline()
+	pipe.HSet(ctx, refreshKey(jti), map[string]interface{}{
+		"session_id": record.SessionID,
+		"user_id":    record.UserID,
+		"parent":     record.Parent,
+	})
+	pipe.Expire(ctx, refreshKey(jti), ttl)
+	hsetIfExistsScript.Eval(ctx, pipe, []string{sessionKey(record.SessionID)}, "refresh_jti", jti)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+func (r *RedisSessionStore) GetRefreshToken(jti string) (*RefreshRecord, error) {
+	m, err := r.rdb.HGetAll(ctx, refreshKey(jti)).Result()
+	if err != nil {
+		return nil, err
+	}
+	if len(m) == 0 {
+		return nil, ErrNotFound
+	}
+	rotatedAt, _ := strconv.ParseInt(m["rotated_at"], 10, 64)
+	return &RefreshRecord{
+		SessionID: m["session_id"],
+		UserID:    m["user_id"],
+		Parent:    m["parent"],
+		Child:     m["child"],
+		RotatedAt: rotatedAt,
+	}, nil
+}
+
+// ClaimRefreshRotation HSETNX 保证并发请求中只有一个完成轮换
+func (r *RedisSessionStore) ClaimRefreshRotation(jti, child string, rotatedAt int64) (bool, error) {
+	ok, err := r.rdb.HSetNX(ctx, refreshKey(jti), "child", child).Result()
+	if err != nil || !ok {
+		return false, err
+	}
+	return true, r.rdb.HSet(ctx, refreshKey(jti), "rotated_at", rotatedAt).Err()
+}
+
+func (r *RedisSessionStore) AppendSecurityEvent(UUID string, event []byte, max int, ttl time.Duration) error {
+	pipe := r.rdb.TxPipeline()
+	pipe.LPush(ctx, securityEventsKey(UUID), event)
+	pipe.LTrim(ctx, securityEventsKey(UUID), 0, int64(max-1))
+	pipe.Expire(ctx, securityEventsKey(UUID), ttl)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+func (r *RedisSessionStore) Set(key, value string, ttl time.Duration) error {
+	return r.rdb.Set(ctx, key, value, ttl).Err()
+}
+
+func (r *RedisSessionStore) SetNX(key, value string, ttl time.Duration) (bool, error) {
+	return r.rdb.SetNX(ctx, key, value, ttl).Result()
+}
+
+func (r *RedisSessionStore) GetDel(key string) (string, error) {
+	v, err := r.rdb.GetDel(ctx, key).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", ErrNotFound
+	}
+	return v, err
+}
+
+func (r *RedisSessionStore) DelIfEqual(key, value string) (bool, error) {
+	n, err := delIfEqualScript.Run(ctx, r.rdb, []string{key}, value).Int()
+	return n == 1, err
+}
+
+func (r *RedisSessionStore) Exists(key string) (bool, error) {
+	n, err := r.rdb.Exists(ctx, key).Result()
+	return n > 0, err
+}
+
+func (r *RedisSessionStore) TTL(key string) (time.Duration, error) {
+	ttl, err := r.rdb.TTL(ctx, key).Result()
+	if err != nil || ttl < 0 {
+		return 0, err
+	}
+	return ttl, nil
+}
+
+func (r *RedisSessionStore) Incr(key string, ttl time.Duration) (int64, error) {
+	count, err := r.rdb.Incr(ctx, key).Result()
+	if err != nil {
+		return 0, err
+	}
+	if count == 1 {
+		r.rdb.Expire(ctx, key, ttl)
+	}
+	return count, nil
+}
+
+func (r *RedisSessionStore) Del(keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	return r.rdb.Del(ctx, keys...).Err()
+}
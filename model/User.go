@@ -10,18 +10,33 @@ import (
 )
 
 type User struct {
-	ID        uint           `gorm:"primaryKey;autoIncrement" json:"id"`
-	UUID      string         `gorm:"type:varchar(36);not null;uniqueIndex" json:"uuid"`                        // 用户唯一标识
-	Account   string         `gorm:"type:varchar(100);uniqueIndex" json:"account" binding:"omitempty"`         // 账号，唯一
-	Username  string         `gorm:"type:varchar(20);not null;uniqueIndex" json:"username" binding:"required"` // 用户名，唯一
-	Password  string         `gorm:"type:varchar(100);not null" json:"password"  binding:"required"`           // 密码
-	Email     string         `gorm:"type:varchar(100);uniqueIndex" json:"email" binding:"omitempty,email"`     // 邮箱，唯一
-	Phone     string         `gorm:"type:varchar(20)" json:"phone" binding:"omitempty"`                        // 手机号
-	Role      string         `gorm:"type:varchar(20);default:user" json:"role"`                                // 角色
-	Status    string         `gorm:"type:varchar(20);default:active" json:"status"`                            // 状态 active/disabled
-	CreatedAt time.Time      `json:"created_at"`                                                               // 创建时间
-	UpdatedAt time.Time      `json:"updated_at"`                                                               // 更新时间
-	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`                                                           // 软删除                                                       // 软删除
+	ID             uint           `gorm:"primaryKey;autoIncrement" json:"id"`
+	UUID           string         `gorm:"type:varchar(36);not null;uniqueIndex" json:"uuid"`                                 // 用户唯一标识
+	Account        string         `gorm:"type:varchar(100);uniqueIndex;default:null" json:"account" binding:"omitempty"`     // 账号，唯一，未填写时存 NULL
+	Username       string         `gorm:"type:varchar(20);not null;uniqueIndex" json:"username" binding:"required"`          // 用户名，唯一
+	Password       string         `gorm:"type:varchar(100);not null" json:"password"  binding:"required"`                    // 密码
+	Email          string         `gorm:"type:varchar(100);uniqueIndex;default:null" json:"email" binding:"omitempty,email"` // 邮箱，唯一，未填写时存 NULL
+	Phone          string         `gorm:"type:varchar(20)" json:"phone" binding:"omitempty"`                                 // 手机号
+	Role           string         `gorm:"type:varchar(20);default:user" json:"role"`                                         // 角色
+	Status         string         `gorm:"type:varchar(20);default:active" json:"status"`                                     // 状态 active/disabled/unverified
+	TOTPSecret     string         `gorm:"type:varchar(64)" json:"-"`                                                         // 两步验证 TOTP 密钥 (Base32)，不允许通过请求体设置
+	TOTPEnabled    bool           `gorm:"default:false" json:"-"`                                                            // 是否已启用两步验证
+	EmailVerified  bool           `gorm:"default:false" json:"-"`                                                            // 邮箱是否已验证
+	DisabledReason string         `gorm:"type:varchar(255)" json:"-"`                                                        // 禁用原因
+	DisabledUntil  *time.Time     `json:"-"`                                                                                 // 禁用到期时间，为空表示永久禁用
+	InvitedBy      *uint          `gorm:"index" json:"-"`                                                                    // 邀请人
+	InviteCodeID   *uint          `json:"-"`                                                                                 // 注册时使用的邀请码
+	CreatedAt      time.Time      `json:"created_at"`                                                                        // 创建时间
+	UpdatedAt      time.Time      `json:"updated_at"`                                                                        // 更新时间
+	DeletedAt      gorm.DeletedAt `gorm:"index" json:"-"`                                                                    // 软删除                                                       // 软删除
+}
+
+// IsDisabled 账号当前是否处于禁用状态，临时禁用到期后视为已解除
+func (u *User) IsDisabled(now time.Time) bool {
+	if u.Status != "disabled" {
+		return false
+	}
+	return u.DisabledUntil == nil || now.Before(*u.DisabledUntil)
 }
 
 // AutoMigrate 创建或更新表结构
@@ -30,6 +45,21 @@ func AutoMigrate(db *gorm.DB) {
 	if err != nil {
 		panic("数据库自动迁移失败: " + err.Error())
 	}
+	// 账号、邮箱可选，唯一索引允许多个 NULL 但不允许多个空字符串，旧数据中的空字符串转为 NULL
+	for _, column := range []string{"account", "email"} {
+		err = db.Unscoped().Model(&User{}).Where(column+" = ?", "").Update(column, nil).Error
+		if err != nil {
+			panic("数据库自动迁移失败: " + err.Error())
+		}
+	}
+}
+
+// NullIfEmpty 可选的唯一列在 Updates 中使用：空字符串写为 NULL
+func NullIfEmpty(value string) interface{} {
+	if value == "" {
+		return nil
+	}
+	return value
 }
 
 // AfterCreate 插入之后执行
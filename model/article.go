@@ -14,44 +14,176 @@ const (
 	Draft     ArticleStatus = iota // 草稿
 	Pending                        // 待审核
 	Published                      // 已发布
+	Rejected                       // 审核未通过
+	Archived                       // 已归档
 )
 
+var articleStatusNames = map[ArticleStatus]string{
+	Draft:     "draft",
+	Pending:   "pending",
+	Published: "published",
+	Rejected:  "rejected",
+	Archived:  "archived",
+}
+
+// String 状态名称
+func (s ArticleStatus) String() string {
+	if name, ok := articleStatusNames[s]; ok {
+		return name
+	}
+	return "unknown"
+}
+
+// ParseArticleStatus 按名称解析状态
+func ParseArticleStatus(name string) (ArticleStatus, bool) {
+	for s, n := range articleStatusNames {
+		if n == name {
+			return s, true
+		}
+	}
+	return 0, false
+}
+
 // Article 表结构映射 (articles)
+// 列表按 (status, 排序列, id) 做游标分页，对应三个联合索引；InnoDB 二级索引自带主键，id 不必写入索引
 type Article struct {
-	ID         int64          `gorm:"primaryKey;autoIncrement" json:"id"`                  // 主键
-	UserID     int64          `gorm:"not null;index" json:"user_id"`                       // 外键关联用户ID
-	Title      string         `gorm:"type:varchar(200);not null" json:"title"`             // 标题
-	Content    string         `gorm:"type:text;not null" json:"content"`                   // 内容
-	Status     ArticleStatus  `gorm:"default: 0" json:"status" enums:"0,1,2"`              // 状态
-	StatusName string         `gorm:"type:varchar(8);default:'draft'" json:"status_name" ` // 状态名称
-	CreatedAt  time.Time      `json:"created_at"`                                          // 创建时间
-	UpdatedAt  time.Time      `json:"updated_at"`                                          // 更新时间
-	DeletedAt  gorm.DeletedAt `gorm:"index" json:"-"`                                      // 软删除
-}
-
-// AfterFind 插入前
+	ID         int64          `gorm:"primaryKey;autoIncrement" json:"id"`                                                                                  // 主键
+	UserID     int64          `gorm:"not null;index" json:"user_id"`                                                                                       // 外键关联用户ID
+	Title      string         `gorm:"type:varchar(200);not null" json:"title"`                                                                             // 标题
+	Content    string         `gorm:"type:text;not null" json:"content"`                                                                                   // 内容
+	Status     ArticleStatus  `gorm:"default: 0;index:idx_status_created;index:idx_status_updated;index:idx_status_views" json:"status" enums:"0,1,2,3,4"` // 状态
+	StatusName string         `gorm:"type:varchar(16);default:'draft'" json:"status_name" `                                                                // 状态名称，由 Status 推导
+	Views      int64          `gorm:"not null;default:0;index:idx_status_views" json:"views"`                                                              // 浏览次数，用于按热度排序
+	CreatedAt  time.Time      `gorm:"index:idx_status_created" json:"created_at"`                                                                          // 创建时间
+	UpdatedAt  time.Time      `gorm:"index:idx_status_updated" json:"updated_at"`                                                                          // 更新时间
+	DeletedAt  gorm.DeletedAt `gorm:"index" json:"-"`                                                                                                      // 软删除
+}
+
+// BeforeSave 保存前根据状态写入状态名称
+func (a *Article) BeforeSave(db *gorm.DB) (err error) {
+	a.StatusName = a.Status.String()
+	return
+}
+
+// AfterFind 查询后根据状态推导状态名称，不依赖表中的 status_name
 func (a *Article) AfterFind(db *gorm.DB) (err error) {
-	switch a.Status {
-	case 1:
-		a.StatusName = "draft"
-	case 2:
-		a.StatusName = "pending"
-	default:
-		a.StatusName = "published"
+	a.StatusName = a.Status.String()
+	return
+}
+
+// ArticleTransition 文章状态流转规则：作者本人（Author）或拥有 Permission 的角色可以执行
+type ArticleTransition struct {
+	From           ArticleStatus
+	To             ArticleStatus
+	Author         bool
+	Permission     string
+	ReasonRequired bool
+}
+
+// articleTransitions 允许的状态流转，不在表中的流转一律非法
+var articleTransitions = []ArticleTransition{
+	{From: Draft, To: Pending, Author: true},                                           // 提交审核
+	{From: Draft, To: Archived, Author: true, Permission: "article:moderate"},          // 归档草稿
+	{From: Pending, To: Draft, Author: true},                                           // 撤回
+	{From: Pending, To: Published, Permission: "article:publish"},                      // 审核通过
+	{From: Pending, To: Rejected, Permission: "article:publish", ReasonRequired: true}, // 驳回
+	{From: Rejected, To: Draft, Author: true},                                          // 修改后重新编辑
+	{From: Rejected, To: Pending, Author: true},                                        // 重新提交
+	{From: Published, To: Archived, Author: true, Permission: "article:moderate"},      // 下线归档
+	{From: Published, To: Draft, Permission: "article:moderate", ReasonRequired: true}, // 撤下发布
+	{From: Archived, To: Draft, Author: true, Permission: "article:moderate"},          // 恢复为草稿
+}
+
+// FindArticleTransition 查找流转规则，不存在时返回 false
+func FindArticleTransition(from, to ArticleStatus) (ArticleTransition, bool) {
+	for _, t := range articleTransitions {
+		if t.From == from && t.To == to {
+			return t, true
+		}
 	}
+	return ArticleTransition{}, false
+}
+
+// ArticleStatusHistory 文章状态变更记录
+type ArticleStatusHistory struct {
+	ID         uint          `gorm:"primaryKey;autoIncrement" json:"id"`
+	ArticleID  int64         `gorm:"not null;index;comment:文章ID" json:"article_id"`
+	FromStatus ArticleStatus `gorm:"not null;comment:原状态" json:"from_status"`
+	ToStatus   ArticleStatus `gorm:"not null;comment:新状态" json:"to_status"`
+	FromName   string        `gorm:"-" json:"from_name"`
+	ToName     string        `gorm:"-" json:"to_name"`
+	ActorID    uint          `gorm:"not null;comment:操作者" json:"actor_id"`
+	Reason     string        `gorm:"type:varchar(255);comment:原因" json:"reason"`
+	CreatedAt  time.Time     `json:"created_at"`
+}
+
+// TableName 表名
+func (ArticleStatusHistory) TableName() string {
+	return "article_status_history"
+}
+
+// AfterFind 推导状态名称
+func (h *ArticleStatusHistory) AfterFind(db *gorm.DB) (err error) {
+	h.FromName, h.ToName = h.FromStatus.String(), h.ToStatus.String()
 	return
 }
 
+// UpdateArticleStatusRequest 修改文章状态
+type UpdateArticleStatusRequest struct {
+	Status string `json:"status" binding:"required,oneof=draft pending published rejected archived"`
+	Reason string `json:"reason" binding:"max=255"`
+}
+
 // ArticleResponse 响应结构体
 type ArticleResponse struct {
-	ID         int64  `json:"id"`
-	UserID     int64  `json:"user_id"`
-	Title      string `json:"title"`
-	Content    string `json:"content"`
-	Status     int    `json:"status"`
-	StatusName string `json:"status_name"`
-	CreatedAt  string `json:"created_at"`
-	UpdatedAt  string `json:"updated_at"`
+	ID         int64      `json:"id"`
+	UserID     int64      `json:"user_id"`
+	Title      string     `json:"title"`
+	Content    string     `json:"content"`
+	Status     int        `json:"status"`
+	StatusName string     `json:"status_name"`
+	Views      int64      `json:"views"`
+	Tags       []string   `json:"tags"`
+	Categories []Category `json:"categories"`
+	CreatedAt  string     `json:"created_at"`
+	UpdatedAt  string     `json:"updated_at"`
+}
+
+// ArticleListQuery 文章列表查询条件，时间使用 RFC3339；cursor 为上一页返回的 next_cursor
+type ArticleListQuery struct {
+	UserID   int64      `form:"user_id"`
+	Status   string     `form:"status" binding:"omitempty,oneof=draft pending published rejected archived"`
+	Tag      string     `form:"tag" binding:"max=32"`
+	Category uint       `form:"category_id"`
+	From     *time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To       *time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+	Sort     string     `form:"sort" binding:"omitempty,oneof=newest updated popular"`
+	Cursor   string     `form:"cursor"`
+	Size     int        `form:"size" binding:"omitempty,min=1,max=100"`
+}
+
+// ArticleRequest 新增或修改文章。修改时 tags、category_ids 省略表示不变，传空数组表示清空
+type ArticleRequest struct {
+	Title       string    `json:"title" binding:"max=200"`
+	Content     string    `json:"content"`
+	Tags        *[]string `json:"tags" binding:"omitempty,max=10,dive,required,max=32"`
+	CategoryIDs *[]uint   `json:"category_ids" binding:"omitempty,max=5"`
+}
+
+// ArticleSearchQuery 文章搜索条件，只搜索已发布的文章
+type ArticleSearchQuery struct {
+	Q      string     `form:"q" binding:"required,max=100"`
+	UserID int64      `form:"user_id"`
+	From   *time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To     *time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+	Page   int        `form:"page" binding:"omitempty,min=1,max=50"`
+	Size   int        `form:"size" binding:"omitempty,min=1,max=50"`
+}
+
+// ArticlePage 文章列表分页结果，next_cursor 为空表示没有下一页
+type ArticlePage struct {
+	Items      []ArticleResponse `json:"items"`
+	NextCursor string            `json:"next_cursor"`
 }
 
 // ArticleToResponse 将 Article 转换为 ArticleResponse
@@ -62,7 +194,8 @@ func ArticleToResponse(a Article) ArticleResponse {
 		Title:      a.Title,
 		Content:    a.Content,
 		Status:     int(a.Status),
-		StatusName: a.StatusName,
+		StatusName: a.Status.String(),
+		Views:      a.Views,
 		CreatedAt:  ti.FormatTime(a.CreatedAt),
 		UpdatedAt:  ti.FormatTime(a.UpdatedAt),
 	}
@@ -70,7 +203,7 @@ func ArticleToResponse(a Article) ArticleResponse {
 
 // AutoMigrateArticle 创建或更新 Article 表结构
 func AutoMigrateArticle(db *gorm.DB) {
-	err := db.AutoMigrate(&Article{})
+	err := db.AutoMigrate(&Article{}, &ArticleStatusHistory{}, &Tag{}, &ArticleTag{}, &Category{}, &ArticleCategory{})
 	if err != nil {
 		panic("Article 表自动迁移失败: " + err.Error())
 	}
@@ -0,0 +1,38 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// AuthEvent 认证审计日志，只追加不修改，超过保留期后由定时任务清理
+type AuthEvent struct {
+	ID          uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID      string    `gorm:"type:varchar(36);index:idx_user_time;comment:用户UUID" json:"user_id"`
+	ActorID     string    `gorm:"type:varchar(36);comment:操作者UUID" json:"actor_id,omitempty"`
+	Type        string    `gorm:"type:varchar(32);not null;index;comment:事件类型" json:"type"`
+	SessionID   string    `gorm:"type:varchar(64);comment:会话ID" json:"session_id,omitempty"`
+	IP          string    `gorm:"type:varchar(45);comment:客户端IP" json:"ip"`
+	UserAgent   string    `gorm:"type:varchar(255);comment:User-Agent" json:"user_agent"`
+	Reason      string    `gorm:"type:varchar(255);comment:原因或说明" json:"reason,omitempty"`
+	Fingerprint string    `gorm:"type:char(64);index;comment:设备指纹，登录成功时记录" json:"-"`
+	Network     string    `gorm:"type:varchar(64);comment:IP网段，登录成功时记录" json:"network,omitempty"`
+	Country     string    `gorm:"type:varchar(2);comment:国家代码，登录成功时记录" json:"country,omitempty"`
+	CreatedAt   time.Time `gorm:"index:idx_user_time;index" json:"created_at"`
+}
+
+// AuthEventQuery 审计日志查询条件，时间使用 RFC3339
+type AuthEventQuery struct {
+	UserID string     `form:"user_id"`
+	Type   string     `form:"type"`
+	From   *time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To     *time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+	Page   int        `form:"page" binding:"omitempty,min=1"`
+	Size   int        `form:"size" binding:"omitempty,min=1,max=200"`
+}
+
+// AutoMigrateAuthEvent 数据库迁移
+func AutoMigrateAuthEvent(db *gorm.DB) error {
+	return db.AutoMigrate(&AuthEvent{})
+}
@@ -0,0 +1,23 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// UserIdentity 第三方身份，一个用户可以关联多个身份提供方
+type UserIdentity struct {
+	ID          uint       `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID      uint       `gorm:"not null;index;comment:所属用户" json:"-"`
+	Provider    string     `gorm:"type:varchar(50);not null;uniqueIndex:idx_provider_subject;comment:身份提供方" json:"provider"`
+	Subject     string     `gorm:"type:varchar(255);not null;uniqueIndex:idx_provider_subject;comment:提供方用户标识 sub" json:"-"`
+	Email       string     `gorm:"type:varchar(100);comment:提供方返回的邮箱" json:"email"`
+	LastLoginAt *time.Time `gorm:"comment:最后登录时间" json:"last_login_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// AutoMigrateIdentity 数据库迁移
+func AutoMigrateIdentity(db *gorm.DB) error {
+	return db.AutoMigrate(&UserIdentity{})
+}
@@ -0,0 +1,35 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// InviteCode 邀请码，注册模式为 invite 时注册必须提供
+type InviteCode struct {
+	ID        uint           `gorm:"primaryKey;autoIncrement" json:"id"`
+	Code      string         `gorm:"type:varchar(32);not null;uniqueIndex;comment:邀请码" json:"code"`
+	CreatorID uint           `gorm:"not null;index;comment:创建者" json:"creator_id"`
+	MaxUses   int            `gorm:"not null;default:1;comment:最多使用次数" json:"max_uses"`
+	Uses      int            `gorm:"not null;default:0;comment:已使用次数" json:"uses"`
+	ExpiresAt *time.Time     `gorm:"comment:过期时间，为空表示永不过期" json:"expires_at"`
+	CreatedAt time.Time      `json:"created_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"` // 作废即软删除
+}
+
+// Usable 邀请码当前是否可用
+func (i *InviteCode) Usable(now time.Time) bool {
+	return i.Uses < i.MaxUses && (i.ExpiresAt == nil || now.Before(*i.ExpiresAt))
+}
+
+// CreateInviteRequest 创建邀请码
+type CreateInviteRequest struct {
+	MaxUses       int `json:"max_uses" binding:"omitempty,min=1"`        // 默认 1
+	ExpiresInDays int `json:"expires_in_days" binding:"omitempty,min=1"` // 默认 register.inviteexpiration
+}
+
+// AutoMigrateInvite 数据库迁移
+func AutoMigrateInvite(db *gorm.DB) error {
+	return db.AutoMigrate(&InviteCode{})
+}
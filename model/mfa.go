@@ -0,0 +1,32 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// RecoveryCode 两步验证恢复码，只保存哈希，每个只能使用一次
+type RecoveryCode struct {
+	ID        uint       `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID    uint       `gorm:"not null;index;comment:所属用户" json:"user_id"`
+	CodeHash  string     `gorm:"type:char(64);not null;uniqueIndex;comment:恢复码SHA256" json:"-"`
+	UsedAt    *time.Time `gorm:"comment:使用时间" json:"used_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// MFACodeRequest 提交动态码或恢复码
+type MFACodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// MFALoginRequest 两步验证登录
+type MFALoginRequest struct {
+	MFAToken string `json:"mfa_token" binding:"required"` // 密码校验通过后返回的临时令牌
+	Code     string `json:"code" binding:"required"`      // 动态码或恢复码
+}
+
+// AutoMigrateMFA 数据库迁移
+func AutoMigrateMFA(db *gorm.DB) error {
+	return db.AutoMigrate(&RecoveryCode{})
+}
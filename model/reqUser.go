@@ -0,0 +1,97 @@
+package model
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// LoginRequest 登录请求，account / email / phone 三选一
+type LoginRequest struct {
+	Account  string `json:"account"`
+	Email    string `json:"email" binding:"omitempty,email"`
+	Phone    string `json:"phone"`
+	Password string `json:"password" binding:"required"`
+}
+
+// Identifier 返回登录标识对应的列名与原始值，只允许提供一个标识
+func (r *LoginRequest) Identifier() (column, value string, err error) {
+	count := 0
+	for col, v := range map[string]string{"account": r.Account, "email": r.Email, "phone": r.Phone} {
+		if v = strings.TrimSpace(v); v != "" {
+			column, value = col, v
+			count++
+		}
+	}
+	if count != 1 {
+		return "", "", errors.New("account、email、phone 必须且只能提供一个")
+	}
+	return column, value, nil
+}
+
+// NormalizeEmail 邮箱统一去空格并转小写
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// RegisterRequest 注册
+type RegisterRequest struct {
+	Account    string `json:"account" binding:"omitempty,max=100"`
+	Username   string `json:"username" binding:"required,max=20"`
+	Password   string `json:"password" binding:"required"`
+	Email      string `json:"email" binding:"omitempty,email"`
+	Phone      string `json:"phone"`
+	InviteCode string `json:"invite_code"` // 注册模式为 invite 时必填
+}
+
+// UpdateUserRequest 修改资料，省略的字段保持不变；phone 传空字符串表示清除手机号
+type UpdateUserRequest struct {
+	Account  *string `json:"account" binding:"omitempty,min=1,max=100"`
+	Username *string `json:"username" binding:"omitempty,min=1,max=20"`
+	Email    *string `json:"email" binding:"omitempty,email"`
+	Phone    *string `json:"phone"`
+}
+
+// ForgotPasswordRequest 申请重置密码
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// ResetPasswordRequest 通过邮件中的令牌重置密码
+type ResetPasswordRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+// VerifyEmailRequest 通过邮件中的令牌验证邮箱
+type VerifyEmailRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// UpdatePasswordRequest 修改密码
+type UpdatePasswordRequest struct {
+	OldPassword string `json:"old_password" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required"`
+}
+
+// DisableUserRequest 禁用用户
+type DisableUserRequest struct {
+	Reason string     `json:"reason" binding:"required,max=255"`
+	Until  *time.Time `json:"until"` // RFC3339，为空表示永久禁用
+}
+
+// UpdateRoleRequest 修改用户角色
+type UpdateRoleRequest struct {
+	Role string `json:"role" binding:"required,max=20"`
+}
+
+// StepUpLoginRequest 异常登录二次验证
+type StepUpLoginRequest struct {
+	StepUpToken string `json:"step_up_token" binding:"required"` // 登录返回的临时令牌
+	Code        string `json:"code" binding:"required,len=6"`    // 邮件中的验证码
+}
+
+// ImpersonateRequest 模拟登录
+type ImpersonateRequest struct {
+	Reason string `json:"reason" binding:"required,max=255"` // 如工单号，写入审计日志
+}
@@ -11,6 +11,10 @@ type UserResponse struct {
 	Phone     string `json:"phone"`
 	Role      string `json:"role"`
 	Status    string `json:"status"`
+	Reason    string `json:"disabled_reason,omitempty"`
+	Until     string `json:"disabled_until,omitempty"`
+	MFA       bool   `json:"mfa_enabled"`
+	Verified  bool   `json:"email_verified"`
 	CreatedAt string `json:"created_at"`
 	UpdatedAt string `json:"updated_at"`
 	DeletedAt string `json:"deleted_at"`
@@ -24,6 +28,10 @@ func UserToResponse(u User) UserResponse {
 	} else {
 		deletedAt = ""
 	}
+	var until string
+	if u.DisabledUntil != nil {
+		until = ti.FormatTime(*u.DisabledUntil)
+	}
 	return UserResponse{
 		UUID:      u.UUID,
 		Username:  u.Username,
@@ -31,6 +39,10 @@ func UserToResponse(u User) UserResponse {
 		Phone:     u.Phone,
 		Role:      u.Role,
 		Status:    u.Status,
+		Reason:    u.DisabledReason,
+		Until:     until,
+		MFA:       u.TOTPEnabled,
+		Verified:  u.EmailVerified,
 		CreatedAt: ti.FormatTime(u.CreatedAt),
 		UpdatedAt: ti.FormatTime(u.UpdatedAt),
 		DeletedAt: deletedAt,
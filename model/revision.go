@@ -0,0 +1,42 @@
+package model
+
+import (
+	"TestGin/util"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ArticleRevision 文章修订记录，每次修改标题或内容生成一个新版本
+type ArticleRevision struct {
+	ID           uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	ArticleID    int64     `gorm:"not null;uniqueIndex:idx_article_version;comment:文章ID" json:"article_id"`
+	Version      int       `gorm:"not null;uniqueIndex:idx_article_version;comment:版本号，从 1 开始" json:"version"`
+	Title        string    `gorm:"type:varchar(200);not null;comment:标题" json:"title"`
+	Content      string    `gorm:"type:text;not null;comment:内容" json:"content,omitempty"`
+	AuthorID     uint      `gorm:"not null;comment:修改人" json:"author_id"`
+	RestoredFrom *int      `gorm:"comment:从哪个版本恢复" json:"restored_from,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// RevisionDiffQuery 比较两个版本，mode 为 unified（按行）或 word（逐词）
+type RevisionDiffQuery struct {
+	From int    `form:"from" binding:"required,min=1"`
+	To   int    `form:"to" binding:"required,min=1"`
+	Mode string `form:"mode" binding:"omitempty,oneof=unified word"`
+}
+
+// RevisionDiff 两个版本的差异：标题始终逐词比较，内容按 mode 返回 unified 或 content
+type RevisionDiff struct {
+	From    int                `json:"from"`
+	To      int                `json:"to"`
+	Mode    string             `json:"mode"`
+	Title   []util.DiffSegment `json:"title"`
+	Unified string             `json:"unified,omitempty"`
+	Content []util.DiffSegment `json:"content,omitempty"`
+}
+
+// AutoMigrateRevision 数据库迁移
+func AutoMigrateRevision(db *gorm.DB) error {
+	return db.AutoMigrate(&ArticleRevision{})
+}
@@ -0,0 +1,71 @@
+package model
+
+import "time"
+
+// Tag 文章标签，ArticleCount 与 article_tags 在同一事务中维护
+type Tag struct {
+	ID           uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	Name         string    `gorm:"type:varchar(32);not null;uniqueIndex;comment:标签名" json:"name"`
+	ArticleCount int       `gorm:"not null;default:0;index;comment:文章数" json:"article_count"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// ArticleTag 文章与标签的关联 (article_tags)
+type ArticleTag struct {
+	ArticleID int64 `gorm:"primaryKey;comment:文章ID" json:"article_id"`
+	TagID     uint  `gorm:"primaryKey;index;comment:标签ID" json:"tag_id"`
+}
+
+// TableName 表名
+func (ArticleTag) TableName() string {
+	return "article_tags"
+}
+
+// Category 文章分类，ParentID 为空表示顶级分类
+type Category struct {
+	ID        uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	ParentID  *uint     `gorm:"index;comment:上级分类" json:"parent_id"`
+	Name      string    `gorm:"type:varchar(32);not null;comment:分类名" json:"name"`
+	Sort      int       `gorm:"not null;default:0;comment:排序，越小越靠前" json:"sort"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ArticleCategory 文章与分类的关联 (article_categories)
+type ArticleCategory struct {
+	ArticleID  int64 `gorm:"primaryKey;comment:文章ID" json:"article_id"`
+	CategoryID uint  `gorm:"primaryKey;index;comment:分类ID" json:"category_id"`
+}
+
+// TableName 表名
+func (ArticleCategory) TableName() string {
+	return "article_categories"
+}
+
+// CategoryNode 分类树节点
+type CategoryNode struct {
+	Category
+	Children []*CategoryNode `json:"children"`
+}
+
+// CategoryRequest 创建或修改分类，parent_id 为 0 表示顶级分类
+type CategoryRequest struct {
+	Name     string `json:"name" binding:"required,max=32"`
+	ParentID uint   `json:"parent_id"`
+	Sort     int    `json:"sort"`
+}
+
+// RenameTagRequest 重命名标签
+type RenameTagRequest struct {
+	Name string `json:"name" binding:"required,max=32"`
+}
+
+// MergeTagsRequest 将 sources 合并到 target，target 不存在时创建
+type MergeTagsRequest struct {
+	Sources []string `json:"sources" binding:"required,min=1,max=50,dive,required,max=32"`
+	Target  string   `json:"target" binding:"required,max=32"`
+}
+
+// TagCloudQuery 标签云查询条件
+type TagCloudQuery struct {
+	Limit int `form:"limit" binding:"omitempty,min=1,max=500"`
+}
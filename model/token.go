@@ -0,0 +1,74 @@
+package model
+
+import (
+	ti "TestGin/util"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// PersonalAccessToken 个人访问令牌，供脚本与 CI 使用，只保存 SHA256
+type PersonalAccessToken struct {
+	ID         uint           `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID     uint           `gorm:"not null;index;comment:所属用户" json:"user_id"`
+	Name       string         `gorm:"type:varchar(100);not null;comment:令牌名称" json:"name"`
+	Prefix     string         `gorm:"type:varchar(16);comment:令牌前几位，便于识别" json:"prefix"`
+	TokenHash  string         `gorm:"type:char(64);not null;uniqueIndex;comment:令牌SHA256" json:"-"`
+	Scopes     string         `gorm:"type:varchar(500);comment:权限范围，逗号分隔" json:"scopes"`
+	ExpiresAt  *time.Time     `gorm:"comment:过期时间，为空表示永不过期" json:"expires_at"`
+	LastUsedAt *time.Time     `gorm:"comment:最后使用时间" json:"last_used_at"`
+	CreatedAt  time.Time      `json:"created_at"`
+	DeletedAt  gorm.DeletedAt `gorm:"index" json:"-"` // 撤销即软删除
+}
+
+// ScopeList 权限范围列表
+func (t *PersonalAccessToken) ScopeList() []string {
+	if t.Scopes == "" {
+		return nil
+	}
+	return strings.Split(t.Scopes, ",")
+}
+
+// CreateTokenRequest 创建个人访问令牌
+type CreateTokenRequest struct {
+	Name          string   `json:"name" binding:"required,max=100"`
+	Scopes        []string `json:"scopes" binding:"required,min=1"`           // 如 article:read、article:*
+	ExpiresInDays int      `json:"expires_in_days" binding:"omitempty,min=1"` // 为空表示永不过期
+}
+
+// TokenResponse 个人访问令牌响应，不包含令牌本身
+type TokenResponse struct {
+	ID         uint     `json:"id"`
+	Name       string   `json:"name"`
+	Prefix     string   `json:"prefix"`
+	Scopes     []string `json:"scopes"`
+	ExpiresAt  string   `json:"expires_at"`
+	LastUsedAt string   `json:"last_used_at"`
+	CreatedAt  string   `json:"created_at"`
+}
+
+// TokenToResponse 转换为响应，未设置的时间为空字符串
+func TokenToResponse(t PersonalAccessToken) TokenResponse {
+	var expiresAt, lastUsedAt string
+	if t.ExpiresAt != nil {
+		expiresAt = ti.FormatTime(*t.ExpiresAt)
+	}
+	if t.LastUsedAt != nil {
+		lastUsedAt = ti.FormatTime(*t.LastUsedAt)
+	}
+	return TokenResponse{
+		ID:         t.ID,
+		Name:       t.Name,
+		Prefix:     t.Prefix,
+		Scopes:     t.ScopeList(),
+		ExpiresAt:  expiresAt,
+		LastUsedAt: lastUsedAt,
+		CreatedAt:  ti.FormatTime(t.CreatedAt),
+	}
+}
+
+// AutoMigrateToken 数据库迁移
+func AutoMigrateToken(db *gorm.DB) error {
+	return db.AutoMigrate(&PersonalAccessToken{})
+}
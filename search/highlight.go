@@ -0,0 +1,94 @@
+package search
+
+import (
+	"html"
+	"strings"
+	"unicode"
+)
+
+// 高亮标签
+const (
+	highlightPre  = "<em>"
+	highlightPost = "</em>"
+)
+
+// matchMask 标记 text 中被任意词命中的字符位置（按字符计，不区分大小写）
+func matchMask(text []rune, terms []string) []bool {
+	lower := make([]rune, len(text))
+	for i, r := range text {
+		lower[i] = unicode.ToLower(r)
+	}
+	mask := make([]bool, len(text))
+	for _, term := range terms {
+		t := []rune(term)
+		if len(t) == 0 {
+			continue
+		}
+		for i := 0; i+len(t) <= len(lower); i++ {
+			if string(lower[i:i+len(t)]) == term {
+				for j := i; j < i+len(t); j++ {
+					mask[j] = true
+				}
+			}
+		}
+	}
+	return mask
+}
+
+// render 转义并用高亮标签包裹连续命中的字符
+func render(text []rune, mask []bool) string {
+	var b strings.Builder
+	for i := 0; i < len(text); {
+		j := i
+		for j < len(text) && mask[j] == mask[i] {
+			j++
+		}
+		if mask[i] {
+			b.WriteString(highlightPre)
+		}
+		b.WriteString(html.EscapeString(string(text[i:j])))
+		if mask[i] {
+			b.WriteString(highlightPost)
+		}
+		i = j
+	}
+	return b.String()
+}
+
+// Highlight 高亮全文中的命中词，terms 为 Tokenize 的结果
+func Highlight(text string, terms []string) string {
+	runes := []rune(text)
+	return render(runes, matchMask(runes, terms))
+}
+
+// Snippet 截取第一个命中词附近 width 个字符作为摘要并高亮，没有命中时取开头
+func Snippet(text string, terms []string, width int) string {
+	runes := []rune(text)
+	mask := matchMask(runes, terms)
+	if len(runes) <= width {
+		return render(runes, mask)
+	}
+	start := 0
+	for i, hit := range mask {
+		if hit {
+			// 命中词前保留四分之一的上下文
+			start = i - width/4
+			break
+		}
+	}
+	if start < 0 {
+		start = 0
+	}
+	if start+width > len(runes) {
+		start = len(runes) - width
+	}
+	end := start + width
+	s := render(runes[start:end], mask[start:end])
+	if start > 0 {
+		s = "…" + s
+	}
+	if end < len(runes) {
+		s += "…"
+	}
+	return s
+}
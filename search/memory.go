@@ -0,0 +1,176 @@
+package search
+
+import (
+	"math"
+	"sort"
+	"sync"
+)
+
+// 相关度参数 (BM25)，标题中的命中按 titleBoost 倍计算
+const (
+	bm25K1     = 1.2
+	bm25B      = 0.75
+	titleBoost = 3
+)
+
+// posting 某个词在一篇文章中的出现次数
+type posting struct {
+	title   int
+	content int
+}
+
+// memoryDoc 已索引的文章与词数
+type memoryDoc struct {
+	Document
+	length int
+}
+
+// MemoryIndex 进程内倒排索引，适合测试与小规模部署；数据不持久化，启动时需从数据库重建
+type MemoryIndex struct {
+	mu          sync.RWMutex
+	docs        map[int64]*memoryDoc
+	postings    map[string]map[int64]*posting
+	totalLength int
+}
+
+// NewMemoryIndex 创建空的内存索引
+func NewMemoryIndex() *MemoryIndex {
+	return &MemoryIndex{
+		docs:     make(map[int64]*memoryDoc),
+		postings: make(map[string]map[int64]*posting),
+	}
+}
+
+// Index 索引文章，已存在时覆盖
+func (m *MemoryIndex) Index(doc Document) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.remove(doc.ID)
+	title, content := Tokenize(doc.Title), Tokenize(doc.Content)
+	add := func(tokens []string, inTitle bool) {
+		for _, t := range tokens {
+			docs := m.postings[t]
+			if docs == nil {
+				docs = make(map[int64]*posting)
+				m.postings[t] = docs
+			}
+			p := docs[doc.ID]
+			if p == nil {
+				p = &posting{}
+				docs[doc.ID] = p
+			}
+			if inTitle {
+				p.title++
+			} else {
+				p.content++
+			}
+		}
+	}
+	add(title, true)
+	add(content, false)
+	length := len(title) + len(content)
+	m.docs[doc.ID] = &memoryDoc{Document: doc, length: length}
+	m.totalLength += length
+	return nil
+}
+
+// Delete 删除文章索引
+func (m *MemoryIndex) Delete(id int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.remove(id)
+	return nil
+}
+
+// remove 调用方需持有写锁
+func (m *MemoryIndex) remove(id int64) {
+	doc, ok := m.docs[id]
+	if !ok {
+		return
+	}
+	for _, t := range uniqueTokens(append(Tokenize(doc.Title), Tokenize(doc.Content)...)) {
+		delete(m.postings[t], id)
+		if len(m.postings[t]) == 0 {
+			delete(m.postings, t)
+		}
+	}
+	m.totalLength -= doc.length
+	delete(m.docs, id)
+}
+
+// Search 返回包含全部查询词的文章，按 BM25 相关度倒序，相同时按 ID 倒序
+func (m *MemoryIndex) Search(q Query) (*Result, error) {
+	terms := uniqueTokens(Tokenize(q.Text))
+	result := &Result{Hits: []Hit{}}
+	if len(terms) == 0 {
+		return result, nil
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	// 从文档最少的词开始求交集
+	sort.Slice(terms, func(i, j int) bool { return len(m.postings[terms[i]]) < len(m.postings[terms[j]]) })
+	n := float64(len(m.docs))
+	avgLength := float64(m.totalLength) / math.Max(n, 1)
+	type scored struct {
+		doc   *memoryDoc
+		score float64
+	}
+	var matches []scored
+	for id := range m.postings[terms[0]] {
+		doc := m.docs[id]
+		if !matchFilter(doc.Document, q) {
+			continue
+		}
+		score := 0.0
+		for _, t := range terms {
+			p, ok := m.postings[t][id]
+			if !ok {
+				score = -1
+				break
+			}
+			df := float64(len(m.postings[t]))
+			idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+			tf := float64(titleBoost*p.title + p.content)
+			score += idf * tf * (bm25K1 + 1) / (tf + bm25K1*(1-bm25B+bm25B*float64(doc.length)/avgLength))
+		}
+		if score >= 0 {
+			matches = append(matches, scored{doc: doc, score: score})
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].score != matches[j].score {
+			return matches[i].score > matches[j].score
+		}
+		return matches[i].doc.ID > matches[j].doc.ID
+	})
+
+	result.Total = int64(len(matches))
+	if q.Offset < len(matches) {
+		matches = matches[q.Offset:]
+		if q.Limit > 0 && len(matches) > q.Limit {
+			matches = matches[:q.Limit]
+		}
+		for _, s := range matches {
+			result.Hits = append(result.Hits, newHit(s.doc.Document, s.score, terms))
+		}
+	}
+	return result, nil
+}
+
+// matchFilter 状态、作者与创建时间筛选
+func matchFilter(doc Document, q Query) bool {
+	if doc.Status != q.Status {
+		return false
+	}
+	if q.UserID != 0 && doc.UserID != q.UserID {
+		return false
+	}
+	if q.From != nil && doc.CreatedAt.Before(*q.From) {
+		return false
+	}
+	if q.To != nil && !doc.CreatedAt.Before(*q.To) {
+		return false
+	}
+	return true
+}
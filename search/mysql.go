@@ -0,0 +1,82 @@
+package search
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// fullTextIndex articles 表上的全文索引名
+const fullTextIndex = "ft_articles_ngram"
+
+// matchExpr 自然语言模式匹配，中文由 ngram 解析器切成二元组
+const matchExpr = "MATCH(title, content) AGAINST (? IN NATURAL LANGUAGE MODE)"
+
+// MySQLSearcher 直接查询 articles 表的 FULLTEXT (ngram) 索引。
+// 索引由 MySQL 在写入文章时维护，Index 与 Delete 不需要做任何事
+type MySQLSearcher struct {
+	DB *gorm.DB
+}
+
+// NewMySQLSearcher 创建 MySQL 搜索后端，全文索引不存在时创建
+func NewMySQLSearcher(db *gorm.DB) (*MySQLSearcher, error) {
+	if !db.Migrator().HasIndex("articles", fullTextIndex) {
+		err := db.Exec("ALTER TABLE articles ADD FULLTEXT INDEX " + fullTextIndex + " (title, content) WITH PARSER ngram").Error
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &MySQLSearcher{DB: db}, nil
+}
+
+// Index 由 MySQL 维护索引
+func (s *MySQLSearcher) Index(Document) error { return nil }
+
+// Delete 由 MySQL 维护索引
+func (s *MySQLSearcher) Delete(int64) error { return nil }
+
+// Search 按 MATCH ... AGAINST 的相关度倒序，高亮在返回后按 Tokenize 的结果处理
+func (s *MySQLSearcher) Search(q Query) (*Result, error) {
+	result := &Result{Hits: []Hit{}}
+	terms := uniqueTokens(Tokenize(q.Text))
+	if len(terms) == 0 {
+		return result, nil
+	}
+	base := func() *gorm.DB {
+		tx := s.DB.Table("articles").
+			Where("deleted_at IS NULL").
+			Where("status = ?", q.Status).
+			Where(matchExpr, q.Text)
+		if q.UserID != 0 {
+			tx = tx.Where("user_id = ?", q.UserID)
+		}
+		if q.From != nil {
+			tx = tx.Where("created_at >= ?", *q.From)
+		}
+		if q.To != nil {
+			tx = tx.Where("created_at < ?", *q.To)
+		}
+		return tx
+	}
+	if err := base().Count(&result.Total).Error; err != nil {
+		return nil, err
+	}
+	var rows []struct {
+		ID        int64
+		UserID    int64
+		Title     string
+		Content   string
+		CreatedAt time.Time
+		Score     float64
+	}
+	err := base().Select("id, user_id, title, content, created_at, "+matchExpr+" AS score", q.Text).
+		Order("score DESC, id DESC").Offset(q.Offset).Limit(q.Limit).Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range rows {
+		doc := Document{ID: r.ID, UserID: r.UserID, Title: r.Title, Content: r.Content, Status: q.Status, CreatedAt: r.CreatedAt}
+		result.Hits = append(result.Hits, newHit(doc, r.Score, terms))
+	}
+	return result, nil
+}
@@ -0,0 +1,62 @@
+package search
+
+import "time"
+
+// Document 被索引的文章
+type Document struct {
+	ID        int64
+	UserID    int64
+	Title     string
+	Content   string
+	Status    int
+	CreatedAt time.Time
+}
+
+// Query 搜索条件，Status 为必填，只返回该状态的文章
+type Query struct {
+	Text   string
+	Status int
+	UserID int64
+	From   *time.Time
+	To     *time.Time
+	Offset int
+	Limit  int
+}
+
+// Hit 搜索结果，标题与摘要中的命中词用 <em></em> 包裹，其余内容已做 HTML 转义
+type Hit struct {
+	ID        int64     `json:"id"`
+	UserID    int64     `json:"user_id"`
+	Title     string    `json:"title"`
+	Snippet   string    `json:"snippet"`
+	Score     float64   `json:"score"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Result 搜索结果页，按相关度倒序
+type Result struct {
+	Total int64 `json:"total"`
+	Hits  []Hit `json:"hits"`
+}
+
+// Searcher 文章搜索后端。Index 对同一 ID 重复调用时覆盖旧内容
+type Searcher interface {
+	Index(doc Document) error
+	Delete(id int64) error
+	Search(q Query) (*Result, error)
+}
+
+// SnippetLength 摘要长度（字符数）
+const SnippetLength = 120
+
+// newHit 生成带高亮的搜索结果
+func newHit(doc Document, score float64, terms []string) Hit {
+	return Hit{
+		ID:        doc.ID,
+		UserID:    doc.UserID,
+		Title:     Highlight(doc.Title, terms),
+		Snippet:   Snippet(doc.Content, terms, SnippetLength),
+		Score:     score,
+		CreatedAt: doc.CreatedAt,
+	}
+}
@@ -0,0 +1,74 @@
+package search
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestTokenize(t *testing.T) {
+	got := Tokenize("Go语言的数据库, MySQL 8.0 与 库")
+	want := []string{"go", "语言", "言的", "的数", "数据", "据库", "mysql", "8", "0", "与", "库"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("分词结果 %q，期望 %q", got, want)
+	}
+}
+
+func TestHighlight(t *testing.T) {
+	terms := Tokenize("数据库")
+	if got := Highlight("<b>分布式数据库</b>设计", terms); got != "&lt;b&gt;分布式<em>数据库</em>&lt;/b&gt;设计" {
+		t.Fatalf("高亮结果 %q", got)
+	}
+	if got := Highlight("Go and go", Tokenize("GO")); got != "<em>Go</em> and <em>go</em>" {
+		t.Fatalf("英文高亮不区分大小写: %q", got)
+	}
+}
+
+func TestSnippet(t *testing.T) {
+	text := "一二三四五六七八九十一二三四五六七八九十数据库一二三四五六七八九十"
+	got := Snippet(text, Tokenize("数据库"), 12)
+	if got != "…八九十<em>数据库</em>一二三四五六…" {
+		t.Fatalf("摘要 %q", got)
+	}
+}
+
+func TestMemoryIndexSearch(t *testing.T) {
+	idx := NewMemoryIndex()
+	now := time.Now()
+	docs := []Document{
+		{ID: 1, UserID: 1, Title: "数据库索引设计", Content: "介绍 B+ 树索引", Status: 2, CreatedAt: now},
+		{ID: 2, UserID: 2, Title: "Go 并发", Content: "数据库连接池与数据库事务", Status: 2, CreatedAt: now},
+		{ID: 3, UserID: 1, Title: "数据库草稿", Content: "未发布", Status: 0, CreatedAt: now},
+		{ID: 4, UserID: 1, Title: "缓存", Content: "数据与库存", Status: 2, CreatedAt: now},
+	}
+	for _, d := range docs {
+		if err := idx.Index(d); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	res, err := idx.Search(Query{Text: "数据库", Status: 2, Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 标题命中排在前面；草稿与只含单个字的文章不返回
+	if res.Total != 2 || res.Hits[0].ID != 1 || res.Hits[1].ID != 2 {
+		t.Fatalf("搜索结果 %+v", res)
+	}
+	if res.Hits[0].Title != "<em>数据库</em>索引设计" {
+		t.Fatalf("标题高亮 %q", res.Hits[0].Title)
+	}
+
+	res, _ = idx.Search(Query{Text: "数据库", Status: 2, UserID: 2, Limit: 10})
+	if res.Total != 1 || res.Hits[0].ID != 2 {
+		t.Fatalf("按作者筛选 %+v", res)
+	}
+
+	// 重新索引覆盖旧内容，删除后不再返回
+	_ = idx.Index(Document{ID: 1, UserID: 1, Title: "索引设计", Content: "B+ 树", Status: 2, CreatedAt: now})
+	_ = idx.Delete(2)
+	res, _ = idx.Search(Query{Text: "数据库", Status: 2, Limit: 10})
+	if res.Total != 0 || len(idx.postings["数据"]) != 2 {
+		t.Fatalf("更新与删除后 %+v, postings %v", res, idx.postings["数据"])
+	}
+}
@@ -0,0 +1,66 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+)
+
+// isCJK 中日韩文字没有空格分词，按二元组切分
+func isCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul)
+}
+
+// Tokenize 分词：连续的中日韩文字切成相邻两字的二元组（只有一个字时保留单字），
+// 字母与数字按单词切分并转为小写，其余字符作为分隔符。与 MySQL ngram 解析器默认的 ngram_token_size=2 一致
+func Tokenize(text string) []string {
+	var (
+		tokens []string
+		run    []rune
+		cjk    bool
+	)
+	flush := func() {
+		switch {
+		case len(run) == 0:
+		case !cjk || len(run) == 1:
+			tokens = append(tokens, string(run))
+		default:
+			for i := 0; i+1 < len(run); i++ {
+				tokens = append(tokens, string(run[i:i+2]))
+			}
+		}
+		run = run[:0]
+	}
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case isCJK(r):
+			if !cjk {
+				flush()
+				cjk = true
+			}
+			run = append(run, r)
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if cjk {
+				flush()
+				cjk = false
+			}
+			run = append(run, r)
+		default:
+			flush()
+		}
+	}
+	flush()
+	return tokens
+}
+
+// uniqueTokens 去重并保持顺序
+func uniqueTokens(tokens []string) []string {
+	seen := make(map[string]bool, len(tokens))
+	out := tokens[:0:0]
+	for _, t := range tokens {
+		if !seen[t] {
+			seen[t] = true
+			out = append(out, t)
+		}
+	}
+	return out
+}
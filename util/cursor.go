@@ -0,0 +1,31 @@
+package util
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+)
+
+// ErrInvalidCursor 游标格式错误或已被篡改
+var ErrInvalidCursor = errors.New("无效的分页游标")
+
+// EncodeCursor 将分页位置编码为不透明游标 (base64url JSON)，客户端只需原样回传
+func EncodeCursor(v interface{}) (string, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+// DecodeCursor 解析 EncodeCursor 生成的游标
+func DecodeCursor(cursor string, v interface{}) error {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return ErrInvalidCursor
+	}
+	if err = json.Unmarshal(raw, v); err != nil {
+		return ErrInvalidCursor
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package util
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+type testCursor struct {
+	Sort string    `json:"s"`
+	Time time.Time `json:"t"`
+	ID   int64     `json:"i"`
+}
+
+func TestCursorRoundTrip(t *testing.T) {
+	in := testCursor{Sort: "newest", Time: time.Date(2024, 5, 1, 8, 30, 0, 123456789, time.UTC), ID: 42}
+	s, err := EncodeCursor(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out testCursor
+	if err = DecodeCursor(s, &out); err != nil {
+		t.Fatal(err)
+	}
+	if out.Sort != in.Sort || out.ID != in.ID || !out.Time.Equal(in.Time) {
+		t.Fatalf("游标解析结果不一致: %+v", out)
+	}
+}
+
+func TestDecodeCursorInvalid(t *testing.T) {
+	var out testCursor
+	for _, s := range []string{"!!!", "bm90LWpzb24"} {
+		if err := DecodeCursor(s, &out); !errors.Is(err, ErrInvalidCursor) {
+			t.Fatalf("%q: 期望 ErrInvalidCursor，实际 %v", s, err)
+		}
+	}
+}
@@ -0,0 +1,240 @@
+package util
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// 差异类型
+const (
+	DiffEqual  = "equal"
+	DiffDelete = "delete"
+	DiffInsert = "insert"
+)
+
+// maxDiffEdits Myers 算法最多计算的编辑距离，超过后整体视为删除旧内容、插入新内容，避免大文本占用过多内存
+const maxDiffEdits = 2000
+
+// DiffSegment 逐词差异中的一段
+type DiffSegment struct {
+	Op   string `json:"op"`
+	Text string `json:"text"`
+}
+
+// diffEdit 编辑脚本中的一步，A、B 为在两个序列中的下标（插入时 A 无意义，删除时 B 无意义）
+type diffEdit struct {
+	op   string
+	a, b int
+}
+
+// SplitWords 按词切分：中日韩文字每个字单独成词，连续的字母数字、连续的空白各为一个词，其余符号单独成词
+func SplitWords(s string) []string {
+	var words []string
+	runes := []rune(s)
+	for i := 0; i < len(runes); {
+		j := i + 1
+		switch r := runes[i]; {
+		case unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul):
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			for j < len(runes) && (unicode.IsLetter(runes[j]) || unicode.IsDigit(runes[j])) &&
+				!unicode.In(runes[j], unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul) {
+				j++
+			}
+		case unicode.IsSpace(r):
+			for j < len(runes) && unicode.IsSpace(runes[j]) {
+				j++
+			}
+		}
+		words = append(words, string(runes[i:j]))
+		i = j
+	}
+	return words
+}
+
+// DiffWords 逐词比较，相邻的同类差异合并为一段
+func DiffWords(a, b string) []DiffSegment {
+	wa, wb := SplitWords(a), SplitWords(b)
+	segments := []DiffSegment{}
+	for _, e := range diffSequences(wa, wb) {
+		text := wa[e.a]
+		if e.op == DiffInsert {
+			text = wb[e.b]
+		}
+		if n := len(segments); n > 0 && segments[n-1].Op == e.op {
+			segments[n-1].Text += text
+			continue
+		}
+		segments = append(segments, DiffSegment{Op: e.op, Text: text})
+	}
+	return segments
+}
+
+// UnifiedDiff 按行比较，输出与 diff -u 相同格式的统一差异，context 为每处改动前后保留的行数
+func UnifiedDiff(a, b, fromName, toName string, context int) string {
+	la, lb := strings.Split(a, "\n"), strings.Split(b, "\n")
+	edits := diffSequences(la, lb)
+	var out strings.Builder
+	fmt.Fprintf(&out, "--- %s\n+++ %s\n", fromName, toName)
+
+	// aLine、bLine 为每一步之前已经过的行数
+	aLine, bLine := make([]int, len(edits)+1), make([]int, len(edits)+1)
+	for i, e := range edits {
+		aLine[i+1], bLine[i+1] = aLine[i], bLine[i]
+		if e.op != DiffInsert {
+			aLine[i+1]++
+		}
+		if e.op != DiffDelete {
+			bLine[i+1]++
+		}
+	}
+	for i := 0; i < len(edits); {
+		if edits[i].op == DiffEqual {
+			i++
+			continue
+		}
+		// 向后合并间隔不超过 2*context 行相同内容的改动
+		start, end := i-context, i
+		if start < 0 {
+			start = 0
+		}
+		for j := i; j < len(edits); j++ {
+			if edits[j].op != DiffEqual {
+				end = j
+			} else if j-end > 2*context {
+				break
+			}
+		}
+		end += context + 1
+		if end > len(edits) {
+			end = len(edits)
+		}
+		fmt.Fprintf(&out, "@@ -%s +%s @@\n", hunkRange(aLine[start], aLine[end]-aLine[start]), hunkRange(bLine[start], bLine[end]-bLine[start]))
+		for _, e := range edits[start:end] {
+			switch e.op {
+			case DiffEqual:
+				out.WriteString(" " + la[e.a] + "\n")
+			case DiffDelete:
+				out.WriteString("-" + la[e.a] + "\n")
+			default:
+				out.WriteString("+" + lb[e.b] + "\n")
+			}
+		}
+		i = end
+	}
+	return out.String()
+}
+
+// hunkRange 统一差异的行范围，行号从 1 开始，空范围时行号为前一行
+func hunkRange(start, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", start+1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
+
+// diffSequences 计算把 a 变为 b 的最短编辑脚本，先去掉相同的首尾
+func diffSequences(a, b []string) []diffEdit {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+	edits := make([]diffEdit, 0, len(a)+len(b))
+	for i := 0; i < prefix; i++ {
+		edits = append(edits, diffEdit{op: DiffEqual, a: i, b: i})
+	}
+	midA, midB := a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+	middle, ok := myers(midA, midB)
+	if !ok {
+		middle = middle[:0]
+		for i := range midA {
+			middle = append(middle, diffEdit{op: DiffDelete, a: i})
+		}
+		for i := range midB {
+			middle = append(middle, diffEdit{op: DiffInsert, b: i})
+		}
+	}
+	for _, e := range middle {
+		e.a, e.b = e.a+prefix, e.b+prefix
+		edits = append(edits, e)
+	}
+	for i := 0; i < suffix; i++ {
+		edits = append(edits, diffEdit{op: DiffEqual, a: len(a) - suffix + i, b: len(b) - suffix + i})
+	}
+	return edits
+}
+
+// myers Myers O(ND) 差异算法，编辑距离超过 maxDiffEdits 时返回 false
+func myers(a, b []string) ([]diffEdit, bool) {
+	n, m := len(a), len(b)
+	maxD := n + m
+	if maxD > maxDiffEdits {
+		maxD = maxDiffEdits
+	}
+	offset := maxD + 1
+	v := make([]int, 2*maxD+3)
+	// trace[d] 保存第 d 轮开始前 k ∈ [-d, d] 的 v，用于回溯
+	var trace [][]int
+	for d := 0; d <= maxD; d++ {
+		snapshot := make([]int, 2*d+1)
+		copy(snapshot, v[offset-d:offset+d+1])
+		trace = append(trace, snapshot)
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x, y = x+1, y+1
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				return backtrack(trace, d, n, m), true
+			}
+		}
+	}
+	return nil, false
+}
+
+// backtrack 从终点沿 trace 回溯出编辑脚本
+func backtrack(trace [][]int, d, x, y int) []diffEdit {
+	var edits []diffEdit
+	for ; d > 0; d-- {
+		prev := trace[d]
+		k := x - y
+		prevK := k - 1
+		if k == -d || (k != d && prev[k-1+d] < prev[k+1+d]) {
+			prevK = k + 1
+		}
+		prevX := prev[prevK+d]
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			x, y = x-1, y-1
+			edits = append(edits, diffEdit{op: DiffEqual, a: x, b: y})
+		}
+		if x == prevX {
+			edits = append(edits, diffEdit{op: DiffInsert, b: prevY})
+		} else {
+			edits = append(edits, diffEdit{op: DiffDelete, a: prevX})
+		}
+		x, y = prevX, prevY
+	}
+	for x > 0 && y > 0 {
+		x, y = x-1, y-1
+		edits = append(edits, diffEdit{op: DiffEqual, a: x, b: y})
+	}
+	for i, j := 0, len(edits)-1; i < j; i, j = i+1, j-1 {
+		edits[i], edits[j] = edits[j], edits[i]
+	}
+	return edits
+}
@@ -0,0 +1,64 @@
+package util
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSplitWords(t *testing.T) {
+	got := SplitWords("Go语言 v1.22，很快")
+	want := []string{"Go", "语", "言", " ", "v1", ".", "22", "，", "很", "快"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("切分结果 %q", got)
+	}
+}
+
+func TestDiffWords(t *testing.T) {
+	got := DiffWords("今天天气很好，适合出门", "今天天气不好，不适合出门 again")
+	want := []DiffSegment{
+		{Op: DiffEqual, Text: "今天天气"},
+		{Op: DiffDelete, Text: "很"},
+		{Op: DiffInsert, Text: "不"},
+		{Op: DiffEqual, Text: "好，"},
+		{Op: DiffInsert, Text: "不"},
+		{Op: DiffEqual, Text: "适合出门"},
+		{Op: DiffInsert, Text: " again"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("逐词差异 %+v", got)
+	}
+}
+
+// TestDiffRoundTrip 相等与删除拼出旧文本，相等与插入拼出新文本
+func TestDiffRoundTrip(t *testing.T) {
+	a := strings.Repeat("甲乙丙丁", 50) + "abc def"
+	b := "开头" + strings.Repeat("甲乙戊丁", 50) + "abc xyz def"
+	var oldText, newText strings.Builder
+	for _, s := range DiffWords(a, b) {
+		if s.Op != DiffInsert {
+			oldText.WriteString(s.Text)
+		}
+		if s.Op != DiffDelete {
+			newText.WriteString(s.Text)
+		}
+	}
+	if oldText.String() != a || newText.String() != b {
+		t.Fatal("差异无法还原原文")
+	}
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	a := "第一行\n第二行\n第三行\n第四行\n第五行\n第六行\n第七行\n第八行"
+	b := "第一行\n第二行（修改）\n第三行\n第四行\n第五行\n第六行\n第七行\n第八行\n第九行"
+	got := UnifiedDiff(a, b, "v1", "v2", 1)
+	want := "--- v1\n+++ v2\n" +
+		"@@ -1,3 +1,3 @@\n 第一行\n-第二行\n+第二行（修改）\n 第三行\n" +
+		"@@ -8 +8,2 @@\n 第八行\n+第九行\n"
+	if got != want {
+		t.Fatalf("统一差异:\n%s", got)
+	}
+	if UnifiedDiff(a, a, "v1", "v1", 3) != "--- v1\n+++ v1\n" {
+		t.Fatal("相同内容不应有改动")
+	}
+}
@@ -0,0 +1,116 @@
+package util
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/netip"
+	"os"
+	"sort"
+	"strings"
+)
+
+// GeoIP 本地 GeoIP 数据库，只查本地文件，不发起网络请求。
+// 文件为 CSV，每行 "网段,国家代码"，如 "1.0.1.0/24,CN"；以 # 开头的行与表头会被忽略，网段之间不应重叠
+type GeoIP struct {
+	ranges []geoRange
+}
+
+type geoRange struct {
+	start, end netip.Addr
+	country    string
+}
+
+// LoadGeoIP 从文件加载 GeoIP 数据库
+func LoadGeoIP(path string) (*GeoIP, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ParseGeoIP(f)
+}
+
+// ParseGeoIP 解析 CSV 格式的 GeoIP 数据
+func ParseGeoIP(r io.Reader) (*GeoIP, error) {
+	g := &GeoIP{}
+	scanner := bufio.NewScanner(r)
+	line := 0
+	first := true // 第一条非注释行可以是表头，文件开头允许有注释
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		header := first
+		first = false
+		fields := strings.Split(text, ",")
+		if len(fields) < 2 {
+			return nil, fmt.Errorf("GeoIP 第 %d 行格式错误", line)
+		}
+		prefix, err := netip.ParsePrefix(strings.TrimSpace(fields[0]))
+		if err != nil {
+			if header {
+				continue // 表头
+			}
+			return nil, fmt.Errorf("GeoIP 第 %d 行网段错误: %v", line, err)
+		}
+		prefix = prefix.Masked()
+		g.ranges = append(g.ranges, geoRange{
+			start:   prefix.Addr(),
+			end:     lastAddr(prefix),
+			country: strings.ToUpper(strings.TrimSpace(fields[1])),
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	sort.Slice(g.ranges, func(i, j int) bool { return g.ranges[i].start.Less(g.ranges[j].start) })
+	return g, nil
+}
+
+// Country 查询 IP 所属国家代码，未知时返回空字符串
+func (g *GeoIP) Country(ip string) string {
+	if g == nil {
+		return ""
+	}
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return ""
+	}
+	addr = addr.Unmap()
+	i := sort.Search(len(g.ranges), func(i int) bool { return addr.Less(g.ranges[i].start) })
+	if i == 0 {
+		return ""
+	}
+	if r := g.ranges[i-1]; addr.Compare(r.end) <= 0 && addr.BitLen() == r.start.BitLen() {
+		return r.country
+	}
+	return ""
+}
+
+// lastAddr 网段中的最后一个地址
+func lastAddr(p netip.Prefix) netip.Addr {
+	b := p.Addr().AsSlice()
+	for i := p.Bits(); i < len(b)*8; i++ {
+		b[i/8] |= 1 << (7 - i%8)
+	}
+	addr, _ := netip.AddrFromSlice(b)
+	return addr
+}
+
+// IPNetwork IP 所在的网段：IPv4 取 /24，IPv6 取 /48，用于判断是否来自常用网络
+func IPNetwork(ip string) string {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return ""
+	}
+	addr = addr.Unmap()
+	bits := 48
+	if addr.Is4() {
+		bits = 24
+	}
+	prefix, _ := addr.Prefix(bits)
+	return prefix.String()
+}
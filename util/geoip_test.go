@@ -0,0 +1,78 @@
+package util
+
+import (
+	"strings"
+	"testing"
+)
+
+const testGeoIP = `network,country
+# 注释
+1.0.1.0/24,CN
+8.8.8.0/24,us
+2001:da8::/32,CN
+10.0.0.0/8,ZZ
+`
+
+func TestGeoIPCountry(t *testing.T) {
+	g, err := ParseGeoIP(strings.NewReader(testGeoIP))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := map[string]string{
+		"1.0.1.0":          "CN",
+		"1.0.1.255":        "CN",
+		"1.0.2.0":          "",
+		"8.8.8.8":          "US",
+		"10.255.255.255":   "ZZ",
+		"::ffff:8.8.8.8":   "US",
+		"2001:da8:8000::1": "CN",
+		"2001:db8::1":      "",
+		"not-an-ip":        "",
+	}
+	for ip, want := range cases {
+		if got := g.Country(ip); got != want {
+			t.Errorf("Country(%q) = %q，期望 %q", ip, got, want)
+		}
+	}
+	var empty *GeoIP
+	if empty.Country("8.8.8.8") != "" {
+		t.Error("未加载数据库时应返回空")
+	}
+}
+
+// TestLoadShippedGeoIP 仓库自带的示例数据以注释开头，表头在第二行
+func TestLoadShippedGeoIP(t *testing.T) {
+	g, err := LoadGeoIP("../config/geoip.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := g.Country("1.0.1.1"); got != "CN" {
+		t.Fatalf("Country(1.0.1.1) = %q，期望 CN", got)
+	}
+	if _, err = ParseGeoIP(strings.NewReader("# 注释\n\nnetwork,country\n8.8.8.0/24,US\n")); err != nil {
+		t.Fatalf("注释后的表头应被跳过: %v", err)
+	}
+}
+
+func TestParseGeoIPRejectsBadLine(t *testing.T) {
+	if _, err := ParseGeoIP(strings.NewReader("1.0.1.0/24,CN\nbad,XX\n")); err == nil {
+		t.Fatal("错误的网段应返回错误")
+	}
+	if _, err := ParseGeoIP(strings.NewReader("network,country\nnetwork,country\n")); err == nil {
+		t.Fatal("只有第一条非注释行可以是表头")
+	}
+}
+
+func TestIPNetwork(t *testing.T) {
+	cases := map[string]string{
+		"203.0.113.45":     "203.0.113.0/24",
+		"::ffff:1.2.3.4":   "1.2.3.0/24",
+		"2001:db8:1:2::99": "2001:db8:1::/48",
+		"":                 "",
+	}
+	for ip, want := range cases {
+		if got := IPNetwork(ip); got != want {
+			t.Errorf("IPNetwork(%q) = %q，期望 %q", ip, got, want)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package util
+
+import (
+	"fmt"
+	"log"
+	"mime"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Mail 邮件内容
+type Mail struct {
+	To      string
+	Subject string
+	Body    string // 纯文本
+}
+
+// Mailer 邮件发送接口
+type Mailer interface {
+	Send(m Mail) error
+}
+
+// SMTPMailer 通过 SMTP 发送邮件
+type SMTPMailer struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
+// Send 发送邮件
+func (s *SMTPMailer) Send(m Mail) error {
+	addr := fmt.Sprintf("%s:%d", s.Host, s.Port)
+	var auth smtp.Auth
+	if s.Username != "" {
+		auth = smtp.PlainAuth("", s.Username, s.Password, s.Host)
+	}
+	return smtp.SendMail(addr, auth, s.From, []string{m.To}, buildMessage(s.From, m))
+}
+
+// FileMailer 将邮件写入目录（开发与测试使用），Dir 为空时只打印日志
+type FileMailer struct {
+	Dir  string
+	From string
+}
+
+// Send 写入 .eml 文件
+func (f *FileMailer) Send(m Mail) error {
+	msg := buildMessage(f.From, m)
+	if f.Dir == "" {
+		log.Printf("📧 邮件 -> %s\n%s", m.To, msg)
+		return nil
+	}
+	if err := os.MkdirAll(f.Dir, 0o755); err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%s-%s.eml", time.Now().Format("20060102-150405.000000"), sanitizeFileName(m.To))
+	return os.WriteFile(filepath.Join(f.Dir, name), msg, 0o644)
+}
+
+// buildMessage 构造 RFC 5322 邮件，主题按 UTF-8 编码
+func buildMessage(from string, m Mail) []byte {
+	var b strings.Builder
+	b.WriteString("From: " + from + "\r\n")
+	b.WriteString("To: " + m.To + "\r\n")
+	b.WriteString("Subject: " + mime.BEncoding.Encode("UTF-8", m.Subject) + "\r\n")
+	b.WriteString("Date: " + time.Now().Format(time.RFC1123Z) + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: 8bit\r\n\r\n")
+	b.WriteString(strings.ReplaceAll(m.Body, "\n", "\r\n"))
+	return []byte(b.String())
+}
+
+func sanitizeFileName(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r == ':' {
+			return '_'
+		}
+		return r
+	}, s)
+}
@@ -0,0 +1,51 @@
+package util
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// LoginAlert 异常登录提醒
+type LoginAlert struct {
+	Username   string
+	Email      string
+	IP         string
+	Country    string
+	DeviceName string
+	UserAgent  string
+	Reasons    []string // 如 "新设备"、"新网络"
+	Time       time.Time
+}
+
+// Notifier 异常登录提醒发送接口
+type Notifier interface {
+	Notify(alert *LoginAlert) error
+}
+
+// MailNotifier 通过邮件发送提醒
+type MailNotifier struct {
+	Mailer Mailer
+}
+
+func (n *MailNotifier) Notify(alert *LoginAlert) error {
+	if alert.Email == "" {
+		return nil
+	}
+	country := alert.Country
+	if country == "" {
+		country = "未知"
+	}
+	body := fmt.Sprintf("%s，你好：\n\n你的账号于 %s 在%s登录。\n\n国家/地区：%s\nIP：%s\n设备：%s %s\n\n如果不是你本人操作，请立即修改密码并在会话管理中撤销该会话。\n",
+		alert.Username, FormatTime(alert.Time), strings.Join(alert.Reasons, "、"), country, alert.IP, alert.DeviceName, alert.UserAgent)
+	return n.Mailer.Send(Mail{To: alert.Email, Subject: "新的登录提醒", Body: body})
+}
+
+// LogNotifier 只打印日志，开发与测试使用
+type LogNotifier struct{}
+
+func (LogNotifier) Notify(alert *LoginAlert) error {
+	log.Printf("🔔 异常登录提醒: 用户 %s %s IP %s (%s)", alert.Username, strings.Join(alert.Reasons, "、"), alert.IP, alert.Country)
+	return nil
+}
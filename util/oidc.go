@@ -0,0 +1,297 @@
+package util
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/golang-jwt/jwt/v5"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// OIDC 授权码 + PKCE 客户端 (OpenID Connect Core 1.0, RFC 7636)。
+// 只依赖标准库和 golang-jwt，ID Token 使用提供方 JWKS 中的 RSA 公钥验签。
+
+// oidcKeyRefresh JWKS 缓存时间，遇到未知 kid 时也会重新拉取
+const oidcKeyRefresh = time.Hour
+
+// OIDCProvider 单个身份提供方
+type OIDCProvider struct {
+	Name         string
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+	HTTPClient   *http.Client
+
+	mu        sync.Mutex
+	discovery *oidcDiscovery
+	keys      map[string]*rsa.PublicKey
+	keysAt    time.Time
+}
+
+// oidcDiscovery 提供方元数据 (/.well-known/openid-configuration)
+type oidcDiscovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+	UserinfoEndpoint      string `json:"userinfo_endpoint"`
+}
+
+// OIDCTokens 令牌端点返回的令牌
+type OIDCTokens struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	IDToken     string `json:"id_token"`
+	ExpiresIn   int    `json:"expires_in"`
+}
+
+// OIDCClaims ID Token 中使用到的声明
+type OIDCClaims struct {
+	Email             string `json:"email"`
+	EmailVerified     bool   `json:"email_verified"`
+	Name              string `json:"name"`
+	PreferredUsername string `json:"preferred_username"`
+	Nonce             string `json:"nonce"`
+	jwt.RegisteredClaims
+}
+
+// GeneratePKCE 生成 PKCE code_verifier 与 S256 code_challenge
+func GeneratePKCE() (verifier, challenge string, err error) {
+	buf := make([]byte, 32)
+	if _, err = rand.Read(buf); err != nil {
+		return "", "", err
+	}
+	verifier = base64.RawURLEncoding.EncodeToString(buf)
+	return verifier, PKCEChallenge(verifier), nil
+}
+
+// PKCEChallenge 计算 S256 code_challenge
+func PKCEChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// RandomToken 生成 URL 安全的随机串，用于 state 与 nonce
+func RandomToken() (string, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+func (p *OIDCProvider) client() *http.Client {
+	if p.HTTPClient != nil {
+		return p.HTTPClient
+	}
+	return http.DefaultClient
+}
+
+// discover 获取并缓存提供方元数据
+func (p *OIDCProvider) discover() (*oidcDiscovery, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.discovery != nil {
+		return p.discovery, nil
+	}
+	var d oidcDiscovery
+	if err := p.getJSON(strings.TrimSuffix(p.Issuer, "/")+"/.well-known/openid-configuration", &d); err != nil {
+		return nil, fmt.Errorf("获取 %s 元数据失败: %w", p.Name, err)
+	}
+	if d.Issuer != p.Issuer {
+		return nil, fmt.Errorf("元数据 issuer 不匹配: %s", d.Issuer)
+	}
+	if d.AuthorizationEndpoint == "" || d.TokenEndpoint == "" || d.JWKSURI == "" {
+		return nil, errors.New("元数据缺少必要的端点")
+	}
+	p.discovery = &d
+	return p.discovery, nil
+}
+
+// AuthCodeURL 生成跳转到提供方的授权地址
+func (p *OIDCProvider) AuthCodeURL(state, nonce, codeChallenge string) (string, error) {
+	d, err := p.discover()
+	if err != nil {
+		return "", err
+	}
+	scopes := p.Scopes
+	if len(scopes) == 0 {
+		scopes = []string{"openid", "email", "profile"}
+	}
+	q := url.Values{}
+	q.Set("response_type", "code")
+	q.Set("client_id", p.ClientID)
+	q.Set("redirect_uri", p.RedirectURL)
+	q.Set("scope", strings.Join(scopes, " "))
+	q.Set("state", state)
+	q.Set("nonce", nonce)
+	q.Set("code_challenge", codeChallenge)
+	q.Set("code_challenge_method", "S256")
+	sep := "?"
+	if strings.Contains(d.AuthorizationEndpoint, "?") {
+		sep = "&"
+	}
+	return d.AuthorizationEndpoint + sep + q.Encode(), nil
+}
+
+// Exchange 用授权码和 code_verifier 换取令牌
+func (p *OIDCProvider) Exchange(code, verifier string) (*OIDCTokens, error) {
+	d, err := p.discover()
+	if err != nil {
+		return nil, err
+	}
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", p.RedirectURL)
+	form.Set("code_verifier", verifier)
+	form.Set("client_id", p.ClientID)
+	req, err := http.NewRequest(http.MethodPost, d.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if p.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(p.ClientID), url.QueryEscape(p.ClientSecret))
+	}
+	resp, err := p.client().Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("换取令牌失败: %s %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	var tokens OIDCTokens
+	if err = json.Unmarshal(body, &tokens); err != nil {
+		return nil, err
+	}
+	if tokens.IDToken == "" {
+		return nil, errors.New("令牌响应中缺少 id_token")
+	}
+	return &tokens, nil
+}
+
+// VerifyIDToken 校验 ID Token 的签名、issuer、audience、有效期与 nonce
+func (p *OIDCProvider) VerifyIDToken(raw, nonce string) (*OIDCClaims, error) {
+	d, err := p.discover()
+	if err != nil {
+		return nil, err
+	}
+	claims := &OIDCClaims{}
+	_, err = jwt.ParseWithClaims(raw, claims, p.keyfunc,
+		jwt.WithValidMethods([]string{"RS256"}),
+		jwt.WithIssuer(d.Issuer),
+		jwt.WithAudience(p.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(time.Minute),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("ID Token 无效: %w", err)
+	}
+	if claims.Subject == "" {
+		return nil, errors.New("ID Token 缺少 sub")
+	}
+	if claims.Nonce != nonce {
+		return nil, errors.New("ID Token nonce 不匹配")
+	}
+	return claims, nil
+}
+
+// keyfunc 根据 kid 选择提供方公钥，未知 kid 时刷新 JWKS（提供方可能已轮换密钥）
+func (p *OIDCProvider) keyfunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	if key := p.cachedKey(kid, false); key != nil {
+		return key, nil
+	}
+	if err := p.fetchKeys(); err != nil {
+		return nil, err
+	}
+	if key := p.cachedKey(kid, true); key != nil {
+		return key, nil
+	}
+	return nil, fmt.Errorf("未知的密钥 kid: %s", kid)
+}
+
+func (p *OIDCProvider) cachedKey(kid string, ignoreAge bool) *rsa.PublicKey {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !ignoreAge && time.Since(p.keysAt) > oidcKeyRefresh {
+		return nil
+	}
+	if key, ok := p.keys[kid]; ok {
+		return key
+	}
+	// 只有一个密钥且令牌未带 kid 时直接使用
+	if kid == "" && len(p.keys) == 1 {
+		for _, key := range p.keys {
+			return key
+		}
+	}
+	return nil
+}
+
+// fetchKeys 拉取提供方 JWKS，只保留 RSA 签名公钥
+func (p *OIDCProvider) fetchKeys() error {
+	d, err := p.discover()
+	if err != nil {
+		return err
+	}
+	var set struct {
+		Keys []struct {
+			Kty string `json:"kty"`
+			Kid string `json:"kid"`
+			Use string `json:"use"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+		} `json:"keys"`
+	}
+	if err = p.getJSON(d.JWKSURI, &set); err != nil {
+		return fmt.Errorf("获取 %s 公钥失败: %w", p.Name, err)
+	}
+	keys := make(map[string]*rsa.PublicKey, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Kty != "RSA" || (k.Use != "" && k.Use != "sig") {
+			continue
+		}
+		n, err1 := base64.RawURLEncoding.DecodeString(k.N)
+		e, err2 := base64.RawURLEncoding.DecodeString(k.E)
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		keys[k.Kid] = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+	}
+	p.mu.Lock()
+	p.keys, p.keysAt = keys, time.Now()
+	p.mu.Unlock()
+	return nil
+}
+
+func (p *OIDCProvider) getJSON(u string, v interface{}) error {
+	resp, err := p.client().Get(u)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s 返回 %s", u, resp.Status)
+	}
+	return json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(v)
+}
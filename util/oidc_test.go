@@ -0,0 +1,282 @@
+package util
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// fakeProvider 进程内的 OIDC 提供方：元数据、授权码换令牌（校验 PKCE）、JWKS
+type fakeProvider struct {
+	t        *testing.T
+	server   *httptest.Server
+	key      *rsa.PrivateKey
+	kid      string
+	clientID string
+	secret   string
+
+	mu    sync.Mutex
+	codes map[string]fakeGrant // code -> 授权信息
+
+	// 用于构造异常的 ID Token
+	audience string
+	expiry   time.Duration
+}
+
+type fakeGrant struct {
+	challenge string
+	nonce     string
+	subject   string
+	email     string
+	verified  bool
+}
+
+func newFakeProvider(t *testing.T) *fakeProvider {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := &fakeProvider{
+		t:        t,
+		key:      key,
+		kid:      "fake-1",
+		clientID: "testgin",
+		secret:   "s3cret",
+		codes:    make(map[string]fakeGrant),
+		expiry:   5 * time.Minute,
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", f.discovery)
+	mux.HandleFunc("/jwks", f.jwks)
+	mux.HandleFunc("/token", f.token)
+	f.server = httptest.NewServer(mux)
+	t.Cleanup(f.server.Close)
+	return f
+}
+
+func (f *fakeProvider) provider() *OIDCProvider {
+	return &OIDCProvider{
+		Name:         "fake",
+		Issuer:       f.server.URL,
+		ClientID:     f.clientID,
+		ClientSecret: f.secret,
+		RedirectURL:  "http://localhost:8080/api/user/oidc/fake/callback",
+		HTTPClient:   f.server.Client(),
+	}
+}
+
+// authorize 模拟用户在提供方登录并同意授权，返回授权码
+func (f *fakeProvider) authorize(authURL, subject, email string, verified bool) string {
+	u, err := url.Parse(authURL)
+	if err != nil {
+		f.t.Fatal(err)
+	}
+	q := u.Query()
+	if q.Get("code_challenge_method") != "S256" || q.Get("code_challenge") == "" {
+		f.t.Fatalf("授权地址缺少 PKCE 参数: %s", authURL)
+	}
+	if q.Get("client_id") != f.clientID || q.Get("response_type") != "code" {
+		f.t.Fatalf("授权地址参数错误: %s", authURL)
+	}
+	code := "code-" + subject
+	f.mu.Lock()
+	f.codes[code] = fakeGrant{
+		challenge: q.Get("code_challenge"),
+		nonce:     q.Get("nonce"),
+		subject:   subject,
+		email:     email,
+		verified:  verified,
+	}
+	f.mu.Unlock()
+	return code
+}
+
+func (f *fakeProvider) discovery(w http.ResponseWriter, r *http.Request) {
+	json.NewEncoder(w).Encode(map[string]string{
+		"issuer":                 f.server.URL,
+		"authorization_endpoint": f.server.URL + "/authorize",
+		"token_endpoint":         f.server.URL + "/token",
+		"jwks_uri":               f.server.URL + "/jwks",
+	})
+}
+
+func (f *fakeProvider) jwks(w http.ResponseWriter, r *http.Request) {
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": f.kid,
+			"use": "sig",
+			"alg": "RS256",
+			"n":   base64.RawURLEncoding.EncodeToString(f.key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(f.key.E)).Bytes()),
+		}},
+	})
+}
+
+func (f *fakeProvider) token(w http.ResponseWriter, r *http.Request) {
+	if id, secret, ok := r.BasicAuth(); !ok || id != f.clientID || secret != f.secret {
+		http.Error(w, `{"error":"invalid_client"}`, http.StatusUnauthorized)
+		return
+	}
+	if err := r.ParseForm(); err != nil || r.PostForm.Get("grant_type") != "authorization_code" {
+		http.Error(w, `{"error":"invalid_request"}`, http.StatusBadRequest)
+		return
+	}
+	f.mu.Lock()
+	grant, ok := f.codes[r.PostForm.Get("code")]
+	delete(f.codes, r.PostForm.Get("code")) // 授权码只能使用一次
+	f.mu.Unlock()
+	if !ok || PKCEChallenge(r.PostForm.Get("code_verifier")) != grant.challenge {
+		http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+		return
+	}
+	json.NewEncoder(w).Encode(OIDCTokens{
+		AccessToken: "at-" + grant.subject,
+		TokenType:   "Bearer",
+		IDToken:     f.idToken(grant),
+		ExpiresIn:   300,
+	})
+}
+
+func (f *fakeProvider) idToken(grant fakeGrant) string {
+	aud := f.audience
+	if aud == "" {
+		aud = f.clientID
+	}
+	claims := OIDCClaims{
+		Email:         grant.email,
+		EmailVerified: grant.verified,
+		Name:          "Test User",
+		Nonce:         grant.nonce,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    f.server.URL,
+			Subject:   grant.subject,
+			Audience:  jwt.ClaimStrings{aud},
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(f.expiry)),
+		},
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = f.kid
+	signed, err := token.SignedString(f.key)
+	if err != nil {
+		f.t.Fatal(err)
+	}
+	return signed
+}
+
+// login 走一遍完整的授权码流程，返回 ID Token 校验结果
+func login(t *testing.T, f *fakeProvider, p *OIDCProvider, tamperVerifier, tamperNonce bool) (*OIDCClaims, error) {
+	t.Helper()
+	verifier, challenge, err := GeneratePKCE()
+	if err != nil {
+		t.Fatal(err)
+	}
+	state, _ := RandomToken()
+	nonce, _ := RandomToken()
+	authURL, err := p.AuthCodeURL(state, nonce, challenge)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(authURL, f.server.URL+"/authorize?") {
+		t.Fatalf("授权地址错误: %s", authURL)
+	}
+	code := f.authorize(authURL, "user-1", "alice@example.com", true)
+	if tamperVerifier {
+		verifier += "x"
+	}
+	tokens, err := p.Exchange(code, verifier)
+	if err != nil {
+		return nil, err
+	}
+	if tamperNonce {
+		nonce = "other"
+	}
+	return p.VerifyIDToken(tokens.IDToken, nonce)
+}
+
+func TestOIDCAuthorizationCodeFlow(t *testing.T) {
+	f := newFakeProvider(t)
+	claims, err := login(t, f, f.provider(), false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claims.Subject != "user-1" || claims.Email != "alice@example.com" || !claims.EmailVerified {
+		t.Fatalf("声明错误: %+v", claims)
+	}
+}
+
+func TestOIDCRejectsWrongCodeVerifier(t *testing.T) {
+	f := newFakeProvider(t)
+	if _, err := login(t, f, f.provider(), true, false); err == nil {
+		t.Fatal("错误的 code_verifier 应当换取失败")
+	}
+}
+
+func TestOIDCRejectsNonceMismatch(t *testing.T) {
+	f := newFakeProvider(t)
+	if _, err := login(t, f, f.provider(), false, true); err == nil {
+		t.Fatal("nonce 不匹配应当校验失败")
+	}
+}
+
+func TestOIDCRejectsWrongAudience(t *testing.T) {
+	f := newFakeProvider(t)
+	f.audience = "another-client"
+	if _, err := login(t, f, f.provider(), false, false); err == nil {
+		t.Fatal("audience 不匹配应当校验失败")
+	}
+}
+
+func TestOIDCRejectsExpiredIDToken(t *testing.T) {
+	f := newFakeProvider(t)
+	f.expiry = -time.Hour
+	if _, err := login(t, f, f.provider(), false, false); err == nil {
+		t.Fatal("过期的 ID Token 应当校验失败")
+	}
+}
+
+func TestOIDCRejectsForeignSignature(t *testing.T) {
+	f := newFakeProvider(t)
+	p := f.provider()
+	if _, err := login(t, f, p, false, false); err != nil {
+		t.Fatal(err)
+	}
+	// 提供方换了一把 JWKS 中没有的私钥，但 kid 不变
+	other, _ := rsa.GenerateKey(rand.Reader, 2048)
+	good := f.key
+	f.key = other
+	_, err := login(t, f, p, false, false)
+	f.key = good
+	if err == nil {
+		t.Fatal("未知私钥签名的 ID Token 应当校验失败")
+	}
+}
+
+func TestOIDCRejectsIssuerMismatch(t *testing.T) {
+	f := newFakeProvider(t)
+	p := f.provider()
+	p.Issuer = f.server.URL + "/"
+	if _, err := p.AuthCodeURL("s", "n", "c"); err == nil {
+		t.Fatal("issuer 与元数据不一致应当失败")
+	}
+}
+
+func TestPKCEChallenge(t *testing.T) {
+	// RFC 7636 附录 B 示例
+	verifier := "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+	if got := PKCEChallenge(verifier); got != "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM" {
+		t.Fatalf("challenge 错误: %s", got)
+	}
+}
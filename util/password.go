@@ -0,0 +1,84 @@
+package util
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// PasswordPolicy 密码策略
+type PasswordPolicy struct {
+	MinLength  int                 // 最小长度（按字符计）
+	MaxLength  int                 // 最大长度，bcrypt 只使用前 72 字节
+	MinClasses int                 // 至少包含的字符类别数：小写、大写、数字、符号
+	Common     map[string]struct{} // 常见或已泄露的密码，小写
+}
+
+// LoadCommonPasswords 读取常见密码列表，每行一个，# 开头为注释
+func LoadCommonPasswords(path string) (map[string]struct{}, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	list := make(map[string]struct{})
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		list[strings.ToLower(line)] = struct{}{}
+	}
+	return list, scanner.Err()
+}
+
+// Validate 校验密码是否满足策略，personal 为用户名、邮箱等不能作为密码的个人信息
+func (p *PasswordPolicy) Validate(password string, personal ...string) error {
+	length := utf8.RuneCountInString(password)
+	if length < p.MinLength {
+		return fmt.Errorf("密码长度至少 %d 位", p.MinLength)
+	}
+	if p.MaxLength > 0 && len(password) > p.MaxLength {
+		return fmt.Errorf("密码长度不能超过 %d 字节", p.MaxLength)
+	}
+	if classes := passwordClasses(password); classes < p.MinClasses {
+		return fmt.Errorf("密码至少包含小写字母、大写字母、数字、符号中的 %d 类", p.MinClasses)
+	}
+	lower := strings.ToLower(password)
+	if _, ok := p.Common[lower]; ok {
+		return errors.New("密码过于常见，请更换")
+	}
+	for _, info := range personal {
+		info = strings.ToLower(strings.TrimSpace(info))
+		if at := strings.IndexByte(info, '@'); at > 0 {
+			info = info[:at]
+		}
+		if len(info) >= 3 && strings.Contains(lower, info) {
+			return errors.New("密码不能包含用户名或邮箱")
+		}
+	}
+	return nil
+}
+
+// passwordClasses 统计密码包含的字符类别数
+func passwordClasses(password string) int {
+	var lower, upper, digit, symbol int
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			lower = 1
+		case unicode.IsUpper(r):
+			upper = 1
+		case unicode.IsDigit(r):
+			digit = 1
+		default:
+			symbol = 1
+		}
+	}
+	return lower + upper + digit + symbol
+}
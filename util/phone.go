@@ -0,0 +1,45 @@
+package util
+
+import (
+	"errors"
+	"strings"
+)
+
+// NormalizePhone 将手机号规范为 E.164 格式（如 +8613800138000）
+// 没有国际区号的号码使用 defaultCountryCode（如 "86"）补全，并去掉国内长途前缀 0
+func NormalizePhone(raw, defaultCountryCode string) (string, error) {
+	s := strings.TrimSpace(raw)
+	international := false
+	switch {
+	case strings.HasPrefix(s, "+"):
+		international = true
+		s = s[1:]
+	case strings.HasPrefix(s, "00"):
+		international = true
+		s = s[2:]
+	}
+
+	var digits strings.Builder
+	for _, r := range s {
+		switch {
+		case r >= '0' && r <= '9':
+			digits.WriteRune(r)
+		case r == ' ' || r == '-' || r == '(' || r == ')' || r == '.':
+			// 忽略常见分隔符
+		default:
+			return "", errors.New("手机号格式错误")
+		}
+	}
+	number := digits.String()
+	if !international {
+		if defaultCountryCode == "" {
+			return "", errors.New("手机号缺少国际区号")
+		}
+		number = strings.TrimPrefix(defaultCountryCode, "+") + strings.TrimLeft(number, "0")
+	}
+	// E.164 最长 15 位，首位不能为 0
+	if len(number) < 8 || len(number) > 15 || number[0] == '0' {
+		return "", errors.New("手机号格式错误")
+	}
+	return "+" + number, nil
+}